@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/importer"
+)
+
+// Imports anime metadata from an AniList or MyAnimeList dump file:
+//
+//	go run ./cmd/import -source anilist -file dumps/anilist.json [-dry-run]
+func main() {
+	source := flag.String("source", "", "dump source: anilist or mal")
+	file := flag.String("file", "", "path to the dump file")
+	dryRun := flag.Bool("dry-run", false, "report changes without writing them")
+	flag.Parse()
+
+	if *source == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	records, err := importer.ParseFile(*file, *source)
+	if err != nil {
+		log.Fatalf("Failed to read dump: %v", err)
+	}
+
+	cfg := config.LoadConfig()
+	if err := database.Connect(cfg.MongoDBURI, cfg.MongoDBDB); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	summary, err := importer.Import(ctx, records, *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	output, _ := json.MarshalIndent(summary, "", "  ")
+	fmt.Println(string(output))
+}
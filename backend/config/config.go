@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -21,6 +23,21 @@ type Config struct {
 	E2SecretKey     string
 	E2Bucket        string
 	BaseURL         string
+	EditorReviewMode bool
+	ImportDir        string
+	SiteURL          string
+	APIV1Sunset      time.Time
+	EmbedHosts       []string
+	AutoAnimeStatus  bool
+	MediaSigningKey  string
+	MediaURLTTL      time.Duration
+	MediaSegmentTTL  time.Duration
+	MediaBindIP      bool
+	MarkerVoteThreshold int
+	SourceReportThreshold int
+	WatchedThreshold float64
+	RatingMinVotes   int
+	ReviewEditWindow time.Duration
 }
 
 func LoadConfig() *Config {
@@ -35,6 +52,101 @@ func LoadConfig() *Config {
 		}
 	}
 
+	// Parse editor review mode
+	editorReviewMode := false
+	if mode := os.Getenv("EDITOR_REVIEW_MODE"); mode != "" {
+		if enabled, err := strconv.ParseBool(mode); err == nil {
+			editorReviewMode = enabled
+		}
+	}
+
+	// Parse the sunset date of the unversioned /api routes
+	var apiV1Sunset time.Time
+	if date, err := time.Parse("2006-01-02", getEnv("API_V1_SUNSET", "2027-06-30")); err == nil {
+		apiV1Sunset = date
+	}
+
+	// Parse automatic anime status transitions
+	autoAnimeStatus := false
+	if mode := os.Getenv("AUTO_ANIME_STATUS"); mode != "" {
+		if enabled, err := strconv.ParseBool(mode); err == nil {
+			autoAnimeStatus = enabled
+		}
+	}
+
+	// Parse the lifetime of signed media URLs
+	mediaURLTTL := 6 * time.Hour
+	if ttl := os.Getenv("MEDIA_URL_TTL"); ttl != "" {
+		if duration, err := time.ParseDuration(ttl); err == nil {
+			mediaURLTTL = duration
+		}
+	}
+
+	// Parse the lifetime of the signed segment URLs inside HLS playlists
+	mediaSegmentTTL := 2 * time.Hour
+	if ttl := os.Getenv("MEDIA_SEGMENT_TTL"); ttl != "" {
+		if duration, err := time.ParseDuration(ttl); err == nil {
+			mediaSegmentTTL = duration
+		}
+	}
+
+	// Parse whether signed media URLs are bound to the client IP
+	mediaBindIP := false
+	if mode := os.Getenv("MEDIA_BIND_IP"); mode != "" {
+		if enabled, err := strconv.ParseBool(mode); err == nil {
+			mediaBindIP = enabled
+		}
+	}
+
+	// Parse the vote score that accepts a user submitted skip marker
+	markerVoteThreshold := 3
+	if threshold := os.Getenv("MARKER_VOTE_THRESHOLD"); threshold != "" {
+		if n, err := strconv.Atoi(threshold); err == nil && n > 0 {
+			markerVoteThreshold = n
+		}
+	}
+
+	// Parse the number of open reports that disables a video source
+	sourceReportThreshold := 5
+	if threshold := os.Getenv("SOURCE_REPORT_THRESHOLD"); threshold != "" {
+		if n, err := strconv.Atoi(threshold); err == nil && n > 0 {
+			sourceReportThreshold = n
+		}
+	}
+
+	// Parse the share of an episode after which it counts as watched
+	watchedThreshold := 0.9
+	if threshold := os.Getenv("WATCHED_THRESHOLD"); threshold != "" {
+		if f, err := strconv.ParseFloat(threshold, 64); err == nil && f > 0 && f <= 1 {
+			watchedThreshold = f
+		}
+	}
+
+	// Parse the vote count at which an anime's weighted rating is halfway
+	// between its own mean and the site mean
+	ratingMinVotes := 10
+	if votes := os.Getenv("RATING_MIN_VOTES"); votes != "" {
+		if n, err := strconv.Atoi(votes); err == nil && n >= 0 {
+			ratingMinVotes = n
+		}
+	}
+
+	// Parse how long a review can be edited after it is written
+	reviewEditWindow := 24 * time.Hour
+	if window := os.Getenv("REVIEW_EDIT_WINDOW"); window != "" {
+		if duration, err := time.ParseDuration(window); err == nil {
+			reviewEditWindow = duration
+		}
+	}
+
+	// Parse the hosts allowed to serve embedded players
+	embedHosts := []string{}
+	for _, host := range strings.Split(os.Getenv("EMBED_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			embedHosts = append(embedHosts, host)
+		}
+	}
+
 	return &Config{
 		MongoDBURI:    getEnv("MONGODB_URI", "mongodb+srv://localhost:27017"),
 		MongoDBDB:     getEnv("MONGODB_DB", "toofy"),
@@ -48,6 +160,21 @@ func LoadConfig() *Config {
 		E2SecretKey:   getEnv("E2_SECRET_ACCESS_KEY", ""),
 		E2Bucket:      getEnv("E2_BUCKET", "cover-animes"),
 		BaseURL:       getEnv("BASE_URL", "http://localhost:8081"),
+		EditorReviewMode: editorReviewMode,
+		ImportDir:        getEnv("IMPORT_DIR", "./imports"),
+		SiteURL:          getEnv("SITE_URL", "https://toovy.netlify.app"),
+		APIV1Sunset:      apiV1Sunset,
+		EmbedHosts:       embedHosts,
+		AutoAnimeStatus:  autoAnimeStatus,
+		MediaSigningKey:  getEnv("MEDIA_SIGNING_KEY", "your-media-signing-key"),
+		MediaURLTTL:      mediaURLTTL,
+		MediaSegmentTTL:  mediaSegmentTTL,
+		MediaBindIP:      mediaBindIP,
+		MarkerVoteThreshold: markerVoteThreshold,
+		SourceReportThreshold: sourceReportThreshold,
+		WatchedThreshold: watchedThreshold,
+		RatingMinVotes:   ratingMinVotes,
+		ReviewEditWindow: reviewEditWindow,
 		CORSOrigins: []string{
 			"http://localhost:3000",
 			"http://localhost:8081",
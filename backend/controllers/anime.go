@@ -10,16 +10,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"toofy-backend/config"
 	"toofy-backend/database"
 	"toofy-backend/models"
 )
 
-type AnimeController struct{}
+type AnimeController struct {
+	cfg *config.Config
+}
 
-func NewAnimeController() *AnimeController {
-	return &AnimeController{}
+func NewAnimeController(cfg *config.Config) *AnimeController {
+	return &AnimeController{cfg: cfg}
 }
 
 // GetAllAnime returns all anime with pagination
@@ -80,19 +83,7 @@ func (ac *AnimeController) GetAllAnime(c *fiber.Ctx) error {
 		animes = []models.Anime{}
 	}
 
-	totalPages := (int(total) + limitNum - 1) / limitNum
-
-	response := models.AnimeListResponse{
-		Success: true,
-		Message: "Anime retrieved successfully",
-	}
-	response.Data.Data = animes
-	response.Data.Total = int(total)
-	response.Data.Page = pageNum
-	response.Data.Limit = limitNum
-	response.Data.TotalPages = totalPages
-
-	return c.Status(fiber.StatusOK).JSON(response)
+	return respondPage(c, "Anime retrieved successfully", "data", animes, pageMeta(total, pageNum, limitNum))
 }
 
 // GetAnimeByID returns a single anime by ID
@@ -121,31 +112,43 @@ func (ac *AnimeController) GetAnimeByID(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Anime retrieved successfully",
-		"data": fiber.Map{
-			"anime": anime,
-		},
+	return respond(c, fiber.StatusOK, "Anime retrieved successfully", "anime", anime)
+}
+
+// GetAnimeBySlug returns a single anime by slug. Slugs retired by a merge
+// redirect to the anime's current slug.
+func (ac *AnimeController) GetAnimeBySlug(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	animeCollection := database.DB.Collection("anime")
+	var anime models.Anime
+
+	err := animeCollection.FindOne(ctx, bson.M{"slug": slug}).Decode(&anime)
+	if err == nil {
+		return respond(c, fiber.StatusOK, "Anime retrieved successfully", "anime", anime)
+	}
+
+	var redirect models.SlugRedirect
+	if err := database.DB.Collection("slug_redirects").FindOne(ctx, bson.M{"_id": slug}).Decode(&redirect); err == nil {
+		if objID, err := primitive.ObjectIDFromHex(redirect.AnimeID); err == nil {
+			if err := animeCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&anime); err == nil && anime.Slug != "" {
+				return c.Redirect(strings.TrimSuffix(c.Path(), slug)+anime.Slug, fiber.StatusMovedPermanently)
+			}
+		}
+	}
+
+	return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+		Success: false,
+		Message: "Anime not found",
 	})
 }
 
 // CreateAnime creates a new anime
 func (ac *AnimeController) CreateAnime(c *fiber.Ctx) error {
-	var req struct {
-		Title            string   `json:"title"`
-		Slug             string   `json:"slug"`
-		AlternativeNames []string `json:"alternativeNames"`
-		Description      string   `json:"description"`
-		CoverUrl         string   `json:"coverUrl"`
-		Genres           []string `json:"genres"`
-		Status           string   `json:"status"`
-		Type             string   `json:"type"`
-		EpisodeCount     int      `json:"episodeCount"`
-		Studio           string   `json:"studio"`
-		Season           string   `json:"season"`
-		SeasonYear       int      `json:"seasonYear"`
-	}
+	var req models.AnimeRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
@@ -155,66 +158,45 @@ func (ac *AnimeController) CreateAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if req.Title == "" {
+	if msg := req.Validate(); msg != "" {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Success: false,
-			Message: "Title is required",
+			Message: msg,
 		})
 	}
 
-	// Validate season if provided
-	if req.Season != "" {
-		validSeasons := map[string]bool{"spring": true, "summer": true, "fall": true, "winter": true}
-		if !validSeasons[req.Season] {
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	anime := req.ToAnime()
+
+	// Refuse likely duplicates unless the caller explicitly forces the insert
+	if c.Query("force") != "true" {
+		candidates, err := findDuplicateCandidates(ctx, &anime)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 				Success: false,
-				Message: "Invalid season. Must be: spring, summer, fall, or winter",
+				Message: "Failed to check for duplicate anime",
+				Error:   err.Error(),
+			})
+		}
+		if len(candidates) > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"message": "Possible duplicate anime found. Pass force=true to create it anyway",
+				"data": fiber.Map{
+					"candidates": candidates,
+				},
 			})
 		}
 	}
 
-	// Validate status
-	validStatuses := map[string]bool{"ongoing": true, "completed": true, "upcoming": true}
-	if !validStatuses[req.Status] {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Success: false,
-			Message: "Invalid status. Must be: ongoing, completed, or upcoming",
-		})
-	}
-
-	// Validate type
-	validTypes := map[string]bool{"TV": true, "Movie": true, "OVA": true, "ONA": true, "Special": true}
-	if !validTypes[req.Type] {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Success: false,
-			Message: "Invalid type. Must be: TV, Movie, OVA, ONA, or Special",
-		})
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	anime := models.Anime{
-		Title:            req.Title,
-		Slug:             req.Slug,
-		AlternativeNames: req.AlternativeNames,
-		Description:      req.Description,
-		CoverUrl:         req.CoverUrl,
-		Genres:           req.Genres,
-		Status:           req.Status,
-		Type:             req.Type,
-		EpisodeCount:     req.EpisodeCount,
-		Studio:           req.Studio,
-		Season:           req.Season,
-		SeasonYear:       req.SeasonYear,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+	// Editors in review mode submit a change request instead of writing directly
+	if ac.requiresReview(c) {
+		return ac.submitChangeRequest(c, ctx, models.ChangeActionCreate, nil, &anime)
 	}
 
-	animeCollection := database.DB.Collection("anime")
-	result, err := animeCollection.InsertOne(ctx, anime)
-	if err != nil {
+	if err := insertAnime(ctx, &anime); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Success: false,
 			Message: "Failed to create anime",
@@ -222,15 +204,7 @@ func (ac *AnimeController) CreateAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	anime.ID = result.InsertedID.(primitive.ObjectID)
-
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "Anime created successfully",
-		"data": fiber.Map{
-			"anime": anime,
-		},
-	})
+	return respond(c, fiber.StatusCreated, "Anime created successfully", "anime", anime)
 }
 
 // UpdateAnime updates an anime
@@ -245,20 +219,7 @@ func (ac *AnimeController) UpdateAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	var req struct {
-		Title            string   `json:"title"`
-		Slug             string   `json:"slug"`
-		AlternativeNames []string `json:"alternativeNames"`
-		Description      string   `json:"description"`
-		CoverUrl         string   `json:"coverUrl"`
-		Genres           []string `json:"genres"`
-		Status           string   `json:"status"`
-		Type             string   `json:"type"`
-		EpisodeCount     int      `json:"episodeCount"`
-		Studio           string   `json:"studio"`
-		Season           string   `json:"season"`
-		SeasonYear       int      `json:"seasonYear"`
-	}
+	var req models.AnimeRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
@@ -268,66 +229,31 @@ func (ac *AnimeController) UpdateAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if req.Title == "" {
+	if msg := req.Validate(); msg != "" {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Success: false,
-			Message: "Title is required",
-		})
-	}
-
-	// Validate season if provided
-	if req.Season != "" {
-		validSeasons := map[string]bool{"spring": true, "summer": true, "fall": true, "winter": true}
-		if !validSeasons[req.Season] {
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-				Success: false,
-				Message: "Invalid season. Must be: spring, summer, fall, or winter",
-			})
-		}
-	}
-
-	// Validate status
-	validStatuses := map[string]bool{"ongoing": true, "completed": true, "upcoming": true}
-	if !validStatuses[req.Status] {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Success: false,
-			Message: "Invalid status. Must be: ongoing, completed, or upcoming",
-		})
-	}
-
-	// Validate type
-	validTypes := map[string]bool{"TV": true, "Movie": true, "OVA": true, "ONA": true, "Special": true}
-	if !validTypes[req.Type] {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Success: false,
-			Message: "Invalid type. Must be: TV, Movie, OVA, ONA, or Special",
+			Message: msg,
 		})
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":            req.Title,
-			"slug":             req.Slug,
-			"alternativeNames": req.AlternativeNames,
-			"description":      req.Description,
-			"coverUrl":         req.CoverUrl,
-			"genres":           req.Genres,
-			"status":           req.Status,
-			"type":             req.Type,
-			"episodeCount":     req.EpisodeCount,
-			"studio":           req.Studio,
-			"season":           req.Season,
-			"seasonYear":       req.SeasonYear,
-			"updatedAt":        time.Now(),
-		},
+	anime := req.ToAnime()
+
+	if ac.requiresReview(c) {
+		var current models.Anime
+		err = database.DB.Collection("anime").FindOne(ctx, bson.M{"_id": objID}).Decode(&current)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Anime not found",
+			})
+		}
+		return ac.submitChangeRequest(c, ctx, models.ChangeActionUpdate, &current, &anime)
 	}
 
-	animeCollection := database.DB.Collection("anime")
-	result, err := animeCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	matched, err := updateAnime(ctx, objID, &anime)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Success: false,
@@ -336,17 +262,14 @@ func (ac *AnimeController) UpdateAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	if result.MatchedCount == 0 {
+	if !matched {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Success: false,
 			Message: "Anime not found",
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Anime updated successfully",
-	})
+	return respond(c, fiber.StatusOK, "Anime updated successfully", "", nil)
 }
 
 // DeleteAnime deletes an anime and its cover image from S3
@@ -376,8 +299,11 @@ func (ac *AnimeController) DeleteAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	// Delete the anime from database
-	result, err := animeCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	if ac.requiresReview(c) {
+		return ac.submitChangeRequest(c, ctx, models.ChangeActionDelete, &anime, nil)
+	}
+
+	deleted, err := deleteAnime(ctx, objID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Success: false,
@@ -386,7 +312,7 @@ func (ac *AnimeController) DeleteAnime(c *fiber.Ctx) error {
 		})
 	}
 
-	if result.DeletedCount == 0 {
+	if !deleted {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Success: false,
 			Message: "Anime not found",
@@ -396,14 +322,145 @@ func (ac *AnimeController) DeleteAnime(c *fiber.Ctx) error {
 	// Note: Image deletion is handled by the frontend via the deleteCover endpoint
 	// The frontend will delete the image from iDrive after the anime is deleted from the database
 
+	return respond(c, fiber.StatusOK, "Anime deleted successfully", "", nil)
+}
+
+// requiresReview reports whether write operations from the current user must
+// go through the editorial approval workflow
+func (ac *AnimeController) requiresReview(c *fiber.Ctx) bool {
+	return ac.cfg.EditorReviewMode && c.Locals("role") == "editor"
+}
+
+// insertAnime stores a new anime and sets its generated ID
+func insertAnime(ctx context.Context, anime *models.Anime) error {
+	anime.CreatedAt = time.Now()
+	anime.UpdatedAt = time.Now()
+
+	result, err := database.DB.Collection("anime").InsertOne(ctx, anime)
+	if err != nil {
+		return err
+	}
+
+	anime.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// updateAnime overwrites the editable fields of an anime. Fields whose value
+// changes count as editor-curated from then on, so metadata imports no longer
+// touch them. It reports whether the anime exists.
+func updateAnime(ctx context.Context, objID primitive.ObjectID, anime *models.Anime) (bool, error) {
+	return updateAnimeFields(ctx, objID, anime, nil)
+}
+
+// updateAnimeFields is updateAnime limited to the named editable fields; the
+// other fields keep their stored values. A nil list writes every field.
+func updateAnimeFields(ctx context.Context, objID primitive.ObjectID, anime *models.Anime, fields []string) (bool, error) {
+	animeCollection := database.DB.Collection("anime")
+
+	var current models.Anime
+	if err := animeCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	set := animeFields(anime)
+	if fields != nil {
+		allowed := map[string]bool{}
+		for _, field := range fields {
+			allowed[field] = true
+		}
+		for key := range set {
+			if !allowed[key] {
+				delete(set, key)
+			}
+		}
+	}
+
+	changed := []string{}
+	for _, change := range diffAnime(&current, anime) {
+		if _, ok := set[change.Field]; ok {
+			changed = append(changed, change.Field)
+		}
+	}
+
+	if _, ok := set["status"]; ok && anime.Status != current.Status {
+		// A status chosen by an editor is no longer moved by episode changes
+		set["autoStatus"] = false
+	}
+	set["updatedAt"] = time.Now()
+	update := bson.M{"$set": set}
+	if len(current.ImportedFields) > 0 && len(changed) > 0 {
+		update["$pull"] = bson.M{"importedFields": bson.M{"$in": changed}}
+	}
+
+	result, err := animeCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := set["status"]; ok && result.MatchedCount > 0 && anime.Status != current.Status {
+		notifyStatusChanged(objID.Hex(), anime.Title, anime.Status)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// deleteAnime removes an anime along with its episodes, its slider entry and
+// everything users attached to either. It reports whether the anime existed.
+func deleteAnime(ctx context.Context, objID primitive.ObjectID) (bool, error) {
+	result, err := database.DB.Collection("anime").DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return false, err
+	}
+	if result.DeletedCount == 0 {
+		return false, nil
+	}
+
 	// Also delete from slider if it exists there
-	sliderCollection := database.DB.Collection("slider")
-	_, _ = sliderCollection.DeleteOne(ctx, bson.M{"animeId": animeID})
+	sliderCollection := database.DB.Collection("sliders")
+	_, _ = sliderCollection.DeleteOne(ctx, bson.M{"animeId": objID.Hex()})
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Anime deleted successfully",
-	})
+	// Data kept per episode is found through the episode IDs, so collect
+	// them before the episodes go
+	episodeIDs, err := database.DB.Collection("episodes").Distinct(ctx, "_id", bson.M{"animeId": objID.Hex()})
+	if err != nil {
+		fmt.Printf("Warning: Failed to find episodes of anime %s: %v\n", objID.Hex(), err)
+	}
+	if len(episodeIDs) > 0 {
+		byEpisode := bson.M{"episodeId": bson.M{"$in": episodeIDs}}
+		_, _ = database.DB.Collection("marker_submissions").DeleteMany(ctx, byEpisode)
+		_, _ = database.DB.Collection("source_reports").DeleteMany(ctx, byEpisode)
+	}
+
+	// Episodes cannot exist without their anime
+	_, _ = database.DB.Collection("episodes").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("library").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("watch_progress").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("ratings").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("reviews").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("follows").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+	_, _ = database.DB.Collection("comments").DeleteMany(ctx, bson.M{"animeId": objID.Hex()})
+
+	return true, nil
+}
+
+// animeFields returns the editable fields of an anime keyed by their bson name
+func animeFields(anime *models.Anime) bson.M {
+	return bson.M{
+		"title":            anime.Title,
+		"slug":             anime.Slug,
+		"alternativeNames": anime.AlternativeNames,
+		"description":      anime.Description,
+		"coverUrl":         anime.CoverUrl,
+		"genres":           anime.Genres,
+		"status":           anime.Status,
+		"type":             anime.Type,
+		"episodeCount":     anime.EpisodeCount,
+		"studio":           anime.Studio,
+		"season":           anime.Season,
+		"seasonYear":       anime.SeasonYear,
+	}
 }
 
 // FixImageURLs updates all anime documents with localhost URLs to use the configured BASE_URL
@@ -465,9 +522,13 @@ func (ac *AnimeController) FixImageURLs(c *fiber.Ctx, cfg *config.Config) error
 		updatedCount++
 	}
 
+	message := fmt.Sprintf("Updated %d anime URLs", updatedCount)
+	if isV2(c) {
+		return respond(c, fiber.StatusOK, message, "", fiber.Map{"updated": updatedCount})
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": fmt.Sprintf("Updated %d anime URLs", updatedCount),
+		"message": message,
 		"updated": updatedCount,
 	})
 }
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// duplicateThreshold is the minimum title similarity for two anime to be
+// reported as likely duplicates
+const duplicateThreshold = 0.85
+
+// animeName is a title or alternative name with its normalized form
+type animeName struct {
+	original   string
+	normalized string
+}
+
+// animeNames returns the title and alternative names of an anime
+func animeNames(anime *models.Anime) []animeName {
+	names := []animeName{}
+	for _, name := range append([]string{anime.Title}, anime.AlternativeNames...) {
+		if normalized := utils.NormalizeTitle(name); normalized != "" {
+			names = append(names, animeName{original: name, normalized: normalized})
+		}
+	}
+	return names
+}
+
+// sameRelease reports whether two anime can describe the same release. Season
+// and year only rule out a match when both entries have them set.
+func sameRelease(a, b *models.Anime) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if a.Season != "" && b.Season != "" && a.Season != b.Season {
+		return false
+	}
+	if a.SeasonYear != 0 && b.SeasonYear != 0 && a.SeasonYear != b.SeasonYear {
+		return false
+	}
+	return true
+}
+
+// matchAnime returns the best similarity between any name of a and any name
+// of b, along with the name of b that matched
+func matchAnime(a, b *models.Anime) (float64, string) {
+	return matchNames(animeNames(a), animeNames(b))
+}
+
+// matchNames is matchAnime on names already returned by animeNames
+func matchNames(a, b []animeName) (float64, string) {
+	best, matched := 0.0, ""
+	for _, na := range a {
+		for _, nb := range b {
+			if score := utils.TitleSimilarity(na.normalized, nb.normalized); score > best {
+				best, matched = score, nb.original
+			}
+		}
+	}
+	return best, matched
+}
+
+// nameKeys returns the character bigrams of an anime's normalized names, or
+// the name itself when it is too short to have any. Two names can only reach
+// duplicateThreshold when they share a key.
+func nameKeys(names []animeName) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, name := range names {
+		runes := []rune(name.normalized)
+		if len(runes) < 2 {
+			add(name.normalized)
+			continue
+		}
+		for i := 0; i < len(runes)-1; i++ {
+			add(string(runes[i : i+2]))
+		}
+	}
+	return keys
+}
+
+// findDuplicateCandidates returns existing anime that likely describe the same
+// show as the given one, best match first
+func findDuplicateCandidates(ctx context.Context, anime *models.Anime) ([]models.DuplicateCandidate, error) {
+	filter := bson.M{"type": anime.Type}
+	if anime.SeasonYear != 0 {
+		filter["seasonYear"] = bson.M{"$in": bson.A{anime.SeasonYear, 0, nil}}
+	}
+	if !anime.ID.IsZero() {
+		filter["_id"] = bson.M{"$ne": anime.ID}
+	}
+
+	cursor, err := database.DB.Collection("anime").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var existing []models.Anime
+	if err = cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+
+	candidates := []models.DuplicateCandidate{}
+	for i := range existing {
+		if !sameRelease(anime, &existing[i]) {
+			continue
+		}
+		if score, matched := matchAnime(anime, &existing[i]); score >= duplicateThreshold {
+			candidates = append(candidates, models.DuplicateCandidate{
+				Anime:       existing[i],
+				Score:       score,
+				MatchedName: matched,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+// GetDuplicateReport returns clusters of existing anime that likely describe
+// the same show
+func (ac *AnimeController) GetDuplicateReport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := database.DB.Collection("anime").Find(ctx, bson.M{})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var animes []models.Anime
+	if err = cursor.All(ctx, &animes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime",
+			Error:   err.Error(),
+		})
+	}
+
+	// Union-find over every likely duplicate pair
+	parent := make([]int, len(animes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// Names are normalized once, and only anime of the same type that share a
+	// name bigram are compared
+	names := make([][]animeName, len(animes))
+	index := map[string][]int{}
+	pairs := [][2]int{}
+	for i := range animes {
+		names[i] = animeNames(&animes[i])
+		compared := map[int]bool{}
+		for _, key := range nameKeys(names[i]) {
+			key = animes[i].Type + "|" + key
+			for _, j := range index[key] {
+				if !compared[j] {
+					compared[j] = true
+					pairs = append(pairs, [2]int{j, i})
+				}
+			}
+			index[key] = append(index[key], i)
+		}
+	}
+
+	scores := map[int]float64{}
+	for _, pair := range pairs {
+		i, j := pair[0], pair[1]
+		if !sameRelease(&animes[i], &animes[j]) {
+			continue
+		}
+		score, _ := matchNames(names[i], names[j])
+		if score < duplicateThreshold {
+			continue
+		}
+		ri, rj := find(i), find(j)
+		if ri != rj {
+			parent[rj] = ri
+			if scores[rj] > scores[ri] {
+				scores[ri] = scores[rj]
+			}
+		}
+		if score > scores[ri] {
+			scores[ri] = score
+		}
+	}
+
+	groups := map[int][]models.Anime{}
+	for i := range animes {
+		root := find(i)
+		groups[root] = append(groups[root], animes[i])
+	}
+
+	clusters := []models.DuplicateCluster{}
+	for root, members := range groups {
+		if len(members) > 1 {
+			clusters = append(clusters, models.DuplicateCluster{
+				Anime: members,
+				Score: scores[root],
+			})
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Score > clusters[j].Score
+	})
+
+	return respond(c, fiber.StatusOK, "Duplicate report generated successfully", "", fiber.Map{
+		"clusters": clusters,
+		"total":    len(clusters),
+	})
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/config"
+	"toofy-backend/importer"
+	"toofy-backend/models"
+)
+
+type ImportController struct {
+	importDir string
+}
+
+func NewImportController(cfg *config.Config) *ImportController {
+	return &ImportController{importDir: cfg.ImportDir}
+}
+
+// ImportAnime creates or enriches anime from an AniList or MyAnimeList dump
+// file stored in the configured import directory
+func (ic *ImportController) ImportAnime(c *fiber.Ctx) error {
+	var req models.ImportRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if req.Source == "" || req.File == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "source and file are required",
+		})
+	}
+
+	// Only files directly inside the import directory can be read
+	name := filepath.Base(req.File)
+	if name != req.File || name == "." || name == ".." {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "file must be a file name inside the import directory",
+		})
+	}
+
+	records, err := importer.ParseFile(filepath.Join(ic.importDir, name), req.Source)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to read dump file",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	summary, err := importer.Import(ctx, records, req.DryRun)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to import anime",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Import completed", "summary", summary)
+}
@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// MergeAnime merges one or more duplicate anime (losers) into a survivor.
+// Field values can be taken from any of the merged entries; episodes and
+// slider entries move to the survivor, loser slugs become redirects and the
+// merge is recorded in the anime history.
+func (ac *AnimeController) MergeAnime(c *fiber.Ctx) error {
+	var req models.MergeAnimeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if req.SurvivorID == "" || len(req.LoserIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "survivorId and at least one loserId are required",
+		})
+	}
+
+	// Parse and de-duplicate IDs
+	survivorObjID, err := primitive.ObjectIDFromHex(req.SurvivorID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid survivor ID",
+		})
+	}
+	loserObjIDs := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{survivorObjID: true}
+	for _, id := range req.LoserIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid loser ID: " + id,
+			})
+		}
+		if seen[objID] {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "An anime cannot be merged into itself or listed twice",
+			})
+		}
+		seen[objID] = true
+		loserObjIDs = append(loserObjIDs, objID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	animeCollection := database.DB.Collection("anime")
+
+	var survivor models.Anime
+	if err := animeCollection.FindOne(ctx, bson.M{"_id": survivorObjID}).Decode(&survivor); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Survivor anime not found",
+		})
+	}
+
+	cursor, err := animeCollection.Find(ctx, bson.M{"_id": bson.M{"$in": loserObjIDs}})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	var losers []models.Anime
+	if err = cursor.All(ctx, &losers); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime",
+			Error:   err.Error(),
+		})
+	}
+	if len(losers) != len(loserObjIDs) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "One or more loser anime not found",
+		})
+	}
+
+	loserHexIDs := make([]string, len(losers))
+	for i := range losers {
+		loserHexIDs[i] = losers[i].ID.Hex()
+	}
+
+	// Episode numbers are unique per anime, so episodes that exist in more
+	// than one of the merged anime must be resolved before anything is written
+	collisions, err := episodeCollisions(ctx, append([]string{survivor.ID.Hex()}, loserHexIDs...))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to check episodes",
+			Error:   err.Error(),
+		})
+	}
+	if len(collisions) > 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episodes exist in more than one of the merged anime, delete or renumber them first: " + strings.Join(collisions, ", "),
+		})
+	}
+
+	// Resolve the merged field values
+	sources := map[string]*models.Anime{survivor.ID.Hex(): &survivor}
+	for i := range losers {
+		sources[losers[i].ID.Hex()] = &losers[i]
+	}
+
+	merged := animeFields(&survivor)
+	for field, sourceID := range req.FieldSources {
+		if _, ok := merged[field]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Unknown field: " + field,
+			})
+		}
+		source, ok := sources[sourceID]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Field source must be the survivor or a loser: " + field,
+			})
+		}
+		merged[field] = animeFields(source)[field]
+	}
+
+	// Unless picked explicitly, keep every loser name as an alternative name
+	if _, picked := req.FieldSources["alternativeNames"]; !picked {
+		merged["alternativeNames"] = mergeAlternativeNames(merged["title"].(string), &survivor, losers)
+	}
+
+	finalSlug, _ := merged["slug"].(string)
+	now := time.Now()
+	userID, _ := c.Locals("userID").(string)
+
+	// Record the merge first so the loser snapshots survive a partial failure
+	history := models.AnimeHistoryEntry{
+		AnimeID:      survivor.ID.Hex(),
+		Action:       models.HistoryActionMerge,
+		UserID:       userID,
+		Merged:       losers,
+		FieldSources: req.FieldSources,
+		CreatedAt:    now,
+	}
+	if _, err := database.DB.Collection("anime_history").InsertOne(ctx, history); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to record merge history",
+			Error:   err.Error(),
+		})
+	}
+
+	// Move episodes over to the survivor
+	episodesResult, err := database.DB.Collection("episodes").UpdateMany(
+		ctx,
+		bson.M{"animeId": bson.M{"$in": loserHexIDs}},
+		bson.M{"$set": bson.M{"animeId": survivor.ID.Hex()}},
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move episodes",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := mergeSliderItems(ctx, survivor.ID.Hex(), loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move slider items",
+			Error:   err.Error(),
+		})
+	}
+
+	// Progress is kept per episode, so it moves along with the episodes
+	if _, err := database.DB.Collection("watch_progress").UpdateMany(
+		ctx,
+		bson.M{"animeId": bson.M{"$in": loserHexIDs}},
+		bson.M{"$set": bson.M{"animeId": survivor.ID.Hex()}},
+	); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move watch progress",
+			Error:   err.Error(),
+		})
+	}
+
+	// Comments on the merged anime and their episodes join the survivor's
+	if _, err := database.DB.Collection("comments").UpdateMany(
+		ctx,
+		bson.M{"animeId": bson.M{"$in": loserHexIDs}},
+		bson.M{"$set": bson.M{"animeId": survivor.ID.Hex()}},
+	); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move comments",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := mergeUserEntries(ctx, "library", survivor.ID.Hex(), loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move library entries",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := mergeUserEntries(ctx, "reviews", survivor.ID.Hex(), loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move reviews",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := mergeUserEntries(ctx, "ratings", survivor.ID.Hex(), loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move ratings",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := mergeUserEntries(ctx, "follows", survivor.ID.Hex(), loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to move follows",
+			Error:   err.Error(),
+		})
+	}
+
+	// Keep retired slugs resolvable
+	retiredSlugs := []string{survivor.Slug}
+	for i := range losers {
+		retiredSlugs = append(retiredSlugs, losers[i].Slug)
+	}
+	if err := addSlugRedirects(ctx, survivor.ID.Hex(), finalSlug, retiredSlugs, loserHexIDs); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to create slug redirects",
+			Error:   err.Error(),
+		})
+	}
+
+	// Everything referencing the losers has moved, so they are deleted last.
+	// This happens before updating the survivor so a slug taken from a loser
+	// does not collide with the unique slug index.
+	if _, err := animeCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": loserObjIDs}}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete merged anime",
+			Error:   err.Error(),
+		})
+	}
+
+	merged["updatedAt"] = now
+	if _, err := animeCollection.UpdateOne(ctx, bson.M{"_id": survivor.ID}, bson.M{"$set": merged}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to update survivor anime",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := recomputeAnimeRatings(ctx, survivor.ID.Hex()); err != nil {
+		fmt.Printf("Warning: Failed to recompute the rating of anime %s: %v\n", survivor.ID.Hex(), err)
+	}
+
+	refreshEpisodeStats(ctx, ac.cfg.AutoAnimeStatus, survivor.ID.Hex())
+
+	var result models.Anime
+	_ = animeCollection.FindOne(ctx, bson.M{"_id": survivor.ID}).Decode(&result)
+
+	return respond(c, fiber.StatusOK, "Anime merged successfully", "", fiber.Map{
+		"anime":         result,
+		"mergedIds":     loserHexIDs,
+		"movedEpisodes": episodesResult.ModifiedCount,
+	})
+}
+
+// episodeCollisions returns the labels of episodes that exist in more than
+// one of the given anime, by type, season and number
+func episodeCollisions(ctx context.Context, animeIDs []string) ([]string, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"animeId": bson.M{"$in": animeIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"type":   "$type",
+				"season": "$season",
+				"number": "$number",
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.type", Value: 1}, {Key: "_id.season", Value: 1}, {Key: "_id.number", Value: 1}}}},
+	}
+	cursor, err := database.DB.Collection("episodes").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Key models.Episode `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	labels := []string{}
+	for i := range groups {
+		labels = append(labels, models.EpisodeLabel(&groups[i].Key))
+	}
+	return labels, nil
+}
+
+// mergeAlternativeNames combines the alternative names of the survivor with
+// the titles and alternative names of the losers, skipping the final title
+// and normalized duplicates
+func mergeAlternativeNames(title string, survivor *models.Anime, losers []models.Anime) []string {
+	seen := map[string]bool{utils.NormalizeTitle(title): true}
+	names := []string{}
+
+	add := func(name string) {
+		normalized := utils.NormalizeTitle(name)
+		if normalized == "" || seen[normalized] {
+			return
+		}
+		seen[normalized] = true
+		names = append(names, name)
+	}
+
+	add(survivor.Title)
+	for _, name := range survivor.AlternativeNames {
+		add(name)
+	}
+	for i := range losers {
+		add(losers[i].Title)
+		for _, name := range losers[i].AlternativeNames {
+			add(name)
+		}
+	}
+	return names
+}
+
+// mergeSliderItems repoints loser slider entries at the survivor, dropping
+// them when the survivor is already featured
+func mergeSliderItems(ctx context.Context, survivorID string, loserIDs []string) error {
+	sliderCollection := database.DB.Collection("sliders")
+
+	count, err := sliderCollection.CountDocuments(ctx, bson.M{"animeId": survivorID})
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"animeId": bson.M{"$in": loserIDs}}
+	if count > 0 {
+		_, err = sliderCollection.DeleteMany(ctx, filter)
+		return err
+	}
+
+	// Keep only the first loser entry so the survivor appears once
+	var first SliderItem
+	err = sliderCollection.FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"order": 1})).Decode(&first)
+	if err != nil {
+		// No loser was featured
+		return nil
+	}
+	if _, err = sliderCollection.UpdateOne(ctx, bson.M{"_id": first.ID}, bson.M{"$set": bson.M{"animeId": survivorID, "updatedAt": time.Now()}}); err != nil {
+		return err
+	}
+	_, err = sliderCollection.DeleteMany(ctx, filter)
+	return err
+}
+
+// addSlugRedirects points retired slugs (and redirects that targeted merged
+// anime) at the survivor
+func addSlugRedirects(ctx context.Context, survivorID, finalSlug string, slugs, mergedIDs []string) error {
+	redirects := database.DB.Collection("slug_redirects")
+
+	if _, err := redirects.UpdateMany(
+		ctx,
+		bson.M{"animeId": bson.M{"$in": mergedIDs}},
+		bson.M{"$set": bson.M{"animeId": survivorID}},
+	); err != nil {
+		return err
+	}
+
+	for _, slug := range slugs {
+		if slug == "" || slug == finalSlug {
+			continue
+		}
+		_, err := redirects.UpdateOne(
+			ctx,
+			bson.M{"_id": slug},
+			bson.M{
+				"$set":         bson.M{"animeId": survivorID},
+				"$setOnInsert": bson.M{"createdAt": time.Now()},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The survivor's current slug must never redirect
+	_, err := redirects.DeleteOne(ctx, bson.M{"_id": finalSlug})
+	return err
+}
+
+// GetAnimeHistory returns the recorded administrative operations for an anime
+func (ac *AnimeController) GetAnimeHistory(c *fiber.Ctx) error {
+	animeID := c.Params("id")
+
+	if _, err := primitive.ObjectIDFromHex(animeID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+	cursor, err := database.DB.Collection("anime_history").Find(ctx, bson.M{"animeId": animeID}, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime history",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AnimeHistoryEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime history",
+			Error:   err.Error(),
+		})
+	}
+
+	if entries == nil {
+		entries = []models.AnimeHistoryEntry{}
+	}
+
+	return respond(c, fiber.StatusOK, "Anime history retrieved successfully", "history", entries)
+}
@@ -123,14 +123,11 @@ func (ac *AuthController) Register(c *fiber.Ctx) error {
 		})
 	}
 
-	response := models.LoginResponse{
-		Success: true,
-		Message: "User registered successfully",
-	}
+	var response models.LoginResponse
 	response.Data.Token = token
 	response.Data.User = newUser
 
-	return c.Status(fiber.StatusCreated).JSON(response)
+	return respond(c, fiber.StatusCreated, "User registered successfully", "", response.Data)
 }
 
 // Login authenticates a user
@@ -190,14 +187,11 @@ func (ac *AuthController) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	response := models.LoginResponse{
-		Success: true,
-		Message: "Login successful",
-	}
+	var response models.LoginResponse
 	response.Data.Token = token
 	response.Data.User = user
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	return respond(c, fiber.StatusOK, "Login successful", "", response.Data)
 }
 
 // GetCurrentUser returns the current authenticated user
@@ -232,11 +226,5 @@ func (ac *AuthController) GetCurrentUser(c *fiber.Ctx) error {
 		// Silently handle error - don't fail the request
 	}
 
-	response := models.AuthResponse{
-		Success: true,
-		Message: "User retrieved successfully",
-	}
-	response.Data.User = user
-
-	return c.Status(fiber.StatusOK).JSON(response)
+	return respond(c, fiber.StatusOK, "User retrieved successfully", "user", user)
 }
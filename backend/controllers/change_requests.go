@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+type ChangeRequestsController struct{}
+
+func NewChangeRequestsController() *ChangeRequestsController {
+	return &ChangeRequestsController{}
+}
+
+// submitChangeRequest stores a pending change request for an anime write made
+// by an editor in review mode. current is nil for create, proposed is nil for delete.
+func (ac *AnimeController) submitChangeRequest(c *fiber.Ctx, ctx context.Context, action string, current, proposed *models.Anime) error {
+	userID, _ := c.Locals("userID").(string)
+
+	request := models.ChangeRequest{
+		Action:      action,
+		Proposed:    proposed,
+		Diff:        diffAnime(current, proposed),
+		Status:      models.ChangeStatusPending,
+		SubmittedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if current != nil {
+		request.AnimeID = current.ID.Hex()
+	}
+
+	if action == models.ChangeActionUpdate && len(request.Diff) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "No changes to submit",
+		})
+	}
+
+	result, err := database.DB.Collection("change_requests").InsertOne(ctx, request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to submit change request",
+			Error:   err.Error(),
+		})
+	}
+
+	request.ID = result.InsertedID.(primitive.ObjectID)
+
+	return respond(c, fiber.StatusAccepted, "Change submitted for review", "changeRequest", request)
+}
+
+// diffAnime returns the editable fields that differ between two versions of
+// an anime. A nil side is treated as an empty anime.
+func diffAnime(before, after *models.Anime) []models.FieldChange {
+	oldFields := bson.M{}
+	if before != nil {
+		oldFields = animeFields(before)
+	}
+	newFields := bson.M{}
+	if after != nil {
+		newFields = animeFields(after)
+	}
+
+	keys := make([]string, 0, len(oldFields)+len(newFields))
+	seen := map[string]bool{}
+	for _, fields := range []bson.M{oldFields, newFields} {
+		for key := range fields {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	diff := []models.FieldChange{}
+	for _, key := range keys {
+		if !fieldsEqual(oldFields[key], newFields[key]) {
+			diff = append(diff, models.FieldChange{
+				Field: key,
+				Old:   oldFields[key],
+				New:   newFields[key],
+			})
+		}
+	}
+	return diff
+}
+
+// fieldsEqual compares two field values, treating nil and empty lists as equal
+func fieldsEqual(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// GetChangeRequests returns change requests filtered by status (pending by default)
+func (crc *ChangeRequestsController) GetChangeRequests(c *fiber.Ctx) error {
+	status := c.Query("status", models.ChangeStatusPending)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "all" {
+		filter["status"] = status
+	}
+
+	// Oldest first so the queue is reviewed in submission order
+	opts := options.Find().SetSort(bson.M{"createdAt": 1})
+	cursor, err := database.DB.Collection("change_requests").Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch change requests",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var requests []models.ChangeRequest
+	if err = cursor.All(ctx, &requests); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse change requests",
+			Error:   err.Error(),
+		})
+	}
+
+	if requests == nil {
+		requests = []models.ChangeRequest{}
+	}
+
+	return respond(c, fiber.StatusOK, "Change requests retrieved successfully", "changeRequests", requests)
+}
+
+// GetChangeRequestByID returns a single change request
+func (crc *ChangeRequestsController) GetChangeRequestByID(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid change request ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var request models.ChangeRequest
+	err = database.DB.Collection("change_requests").FindOne(ctx, bson.M{"_id": objID}).Decode(&request)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Change request not found",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Change request retrieved successfully", "changeRequest", request)
+}
+
+// ApproveChangeRequest applies a pending change request
+func (crc *ChangeRequestsController) ApproveChangeRequest(c *fiber.Ctx) error {
+	return crc.review(c, models.ChangeStatusApproved)
+}
+
+// RejectChangeRequest rejects a pending change request without applying it
+func (crc *ChangeRequestsController) RejectChangeRequest(c *fiber.Ctx) error {
+	return crc.review(c, models.ChangeStatusRejected)
+}
+
+// review claims a pending change request for the given decision. For approvals
+// the change is applied afterwards; if applying fails, or a created anime
+// turns out to be a likely duplicate, the claim is released so the request
+// stays pending.
+func (crc *ChangeRequestsController) review(c *fiber.Ctx, decision string) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid change request ID",
+		})
+	}
+
+	var req models.ReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid request body",
+				Error:   err.Error(),
+			})
+		}
+	}
+
+	if decision == models.ChangeStatusRejected && req.Comment == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "A comment is required when rejecting a change",
+		})
+	}
+
+	reviewerID, _ := c.Locals("userID").(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := database.DB.Collection("change_requests")
+	now := time.Now()
+
+	// Claim the request atomically so two admins cannot review it at once
+	var request models.ChangeRequest
+	err = collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objID, "status": models.ChangeStatusPending},
+		bson.M{"$set": bson.M{
+			"status":        decision,
+			"reviewedBy":    reviewerID,
+			"reviewComment": req.Comment,
+			"reviewedAt":    now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Change request not found or already reviewed",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to review change request",
+			Error:   err.Error(),
+		})
+	}
+
+	if decision == models.ChangeStatusApproved {
+		release := func() {
+			_, _ = collection.UpdateOne(
+				ctx,
+				bson.M{"_id": objID},
+				bson.M{
+					"$set":   bson.M{"status": models.ChangeStatusPending},
+					"$unset": bson.M{"reviewedBy": "", "reviewComment": "", "reviewedAt": ""},
+				},
+			)
+		}
+
+		// Anime created since the request was submitted may duplicate it, so
+		// creations are checked again like direct ones
+		if request.Action == models.ChangeActionCreate && request.Proposed != nil && c.Query("force") != "true" {
+			proposed := *request.Proposed
+			proposed.ID = primitive.NilObjectID
+			candidates, err := findDuplicateCandidates(ctx, &proposed)
+			if err != nil {
+				release()
+				return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+					Success: false,
+					Message: "Failed to check for duplicate anime",
+					Error:   err.Error(),
+				})
+			}
+			if len(candidates) > 0 {
+				release()
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"success": false,
+					"message": "Possible duplicate anime found. Pass force=true to approve it anyway",
+					"data": fiber.Map{
+						"candidates": candidates,
+					},
+				})
+			}
+		}
+
+		if err := applyChangeRequest(ctx, &request); err != nil {
+			release()
+			status := fiber.StatusInternalServerError
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			}
+			return c.Status(status).JSON(models.ErrorResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	message := "Change request approved"
+	if decision == models.ChangeStatusRejected {
+		message = "Change request rejected"
+	}
+
+	return respond(c, fiber.StatusOK, message, "changeRequest", request)
+}
+
+// applyChangeRequest performs the anime write described by a change request.
+// Each action touches a single anime document, so it is applied atomically.
+func applyChangeRequest(ctx context.Context, request *models.ChangeRequest) error {
+	switch request.Action {
+	case models.ChangeActionCreate:
+		if request.Proposed == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Change request has no proposed anime")
+		}
+		anime := *request.Proposed
+		anime.ID = primitive.NilObjectID
+		if err := insertAnime(ctx, &anime); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return fiber.NewError(fiber.StatusConflict, "An anime with this slug already exists")
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to create anime")
+		}
+		request.AnimeID = anime.ID.Hex()
+		_, _ = database.DB.Collection("change_requests").UpdateOne(
+			ctx,
+			bson.M{"_id": request.ID},
+			bson.M{"$set": bson.M{"animeId": request.AnimeID}},
+		)
+
+	case models.ChangeActionUpdate:
+		if request.Proposed == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Change request has no proposed anime")
+		}
+		objID, err := primitive.ObjectIDFromHex(request.AnimeID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid anime ID")
+		}
+		// Only the reviewed fields are written, so edits made to other
+		// fields since the request was submitted are kept
+		fields := make([]string, 0, len(request.Diff))
+		for _, change := range request.Diff {
+			fields = append(fields, change.Field)
+		}
+		matched, err := updateAnimeFields(ctx, objID, request.Proposed, fields)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to update anime")
+		}
+		if !matched {
+			return fiber.NewError(fiber.StatusNotFound, "Anime not found")
+		}
+
+	case models.ChangeActionDelete:
+		objID, err := primitive.ObjectIDFromHex(request.AnimeID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid anime ID")
+		}
+		deleted, err := deleteAnime(ctx, objID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete anime")
+		}
+		if !deleted {
+			return fiber.NewError(fiber.StatusNotFound, "Anime not found")
+		}
+
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Unknown change request action")
+	}
+
+	return nil
+}
@@ -0,0 +1,695 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/handlers"
+	"toofy-backend/models"
+)
+
+type CommentsController struct{}
+
+func NewCommentsController() *CommentsController {
+	return &CommentsController{}
+}
+
+// GetAnimeComments lists the top-level comments on an anime page, pinned
+// ones first
+func (cc *CommentsController) GetAnimeComments(c *fiber.Ctx) error {
+	sort, ok := commentSort(c.Query("sort", models.CommentSortTop))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid sort. Must be: top or newest",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"animeId": c.Params("id"), "episodeId": nil, "parentId": nil}
+	return cc.listComments(c, ctx, filter, sort)
+}
+
+// GetEpisodeComments lists the top-level comments on an episode page, pinned
+// ones first
+func (cc *CommentsController) GetEpisodeComments(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	sort, ok := commentSort(c.Query("sort", models.CommentSortTop))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid sort. Must be: top or newest",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role, _ := c.Locals("role").(string)
+	episode, err := findVisibleEpisode(ctx, objID, role)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch episode")
+	}
+
+	filter := bson.M{"animeId": episode.AnimeID, "episodeId": objID, "parentId": nil}
+	return cc.listComments(c, ctx, filter, sort)
+}
+
+// GetCommentReplies lists the direct replies to a comment, oldest first
+func (cc *CommentsController) GetCommentReplies(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	parent, err := findComment(ctx, objID)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch comment")
+	}
+	if parent.EpisodeID != nil {
+		role, _ := c.Locals("role").(string)
+		if _, err := findVisibleEpisode(ctx, *parent.EpisodeID, role); err != nil {
+			return errorResponse(c, err, "Failed to fetch episode")
+		}
+	}
+
+	sort := bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}
+	return cc.listComments(c, ctx, bson.M{"parentId": objID}, sort)
+}
+
+// CreateAnimeComment adds a top-level comment to an anime page
+func (cc *CommentsController) CreateAnimeComment(c *fiber.Ctx) error {
+	req, err := parseCommentRequest(c)
+	if err != nil {
+		return errorResponse(c, err, "Invalid request body")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ensureAnimeExists(ctx, c.Params("id")); err != nil {
+		return errorResponse(c, err, "Failed to create comment")
+	}
+
+	comment := models.Comment{AnimeID: c.Params("id")}
+	return cc.insertComment(c, ctx, &comment, req, true)
+}
+
+// CreateEpisodeComment adds a top-level comment to an episode page
+func (cc *CommentsController) CreateEpisodeComment(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	req, err := parseCommentRequest(c)
+	if err != nil {
+		return errorResponse(c, err, "Invalid request body")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role, _ := c.Locals("role").(string)
+	episode, err := findVisibleEpisode(ctx, objID, role)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch episode")
+	}
+
+	comment := models.Comment{AnimeID: episode.AnimeID, EpisodeID: &episode.ID}
+	return cc.insertComment(c, ctx, &comment, req, episodeVisibleTo("", episode))
+}
+
+// ReplyToComment adds a reply to a comment. Replies nest up to
+// models.MaxCommentDepth deep and locked threads take no replies.
+func (cc *CommentsController) ReplyToComment(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	req, err := parseCommentRequest(c)
+	if err != nil {
+		return errorResponse(c, err, "Invalid request body")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	parent, err := findComment(ctx, objID)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch comment")
+	}
+
+	if parent.Deleted || parent.Hidden {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You cannot reply to a deleted or hidden comment",
+		})
+	}
+	if parent.Depth >= models.MaxCommentDepth {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: fmt.Sprintf("Replies cannot be nested more than %d levels deep", models.MaxCommentDepth),
+		})
+	}
+
+	root := parent
+	if parent.RootID != nil {
+		if root, err = findComment(ctx, *parent.RootID); err != nil {
+			return errorResponse(c, err, "Failed to fetch comment")
+		}
+	}
+	if root.Locked {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "This thread is locked",
+		})
+	}
+
+	public := true
+	if parent.EpisodeID != nil {
+		role, _ := c.Locals("role").(string)
+		episode, err := findVisibleEpisode(ctx, *parent.EpisodeID, role)
+		if err != nil {
+			return errorResponse(c, err, "Failed to fetch episode")
+		}
+		public = episodeVisibleTo("", episode)
+	}
+
+	comment := models.Comment{
+		AnimeID:   parent.AnimeID,
+		EpisodeID: parent.EpisodeID,
+		ParentID:  &parent.ID,
+		RootID:    &root.ID,
+		Depth:     parent.Depth + 1,
+	}
+	return cc.insertComment(c, ctx, &comment, req, public)
+}
+
+// UpdateComment edits the current user's comment
+func (cc *CommentsController) UpdateComment(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	req, err := parseCommentRequest(c)
+	if err != nil {
+		return errorResponse(c, err, "Invalid request body")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := c.Locals("userID").(string)
+	comment, err := findOwnComment(ctx, objID, userID)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch comment")
+	}
+
+	if comment.Hidden {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "This comment was hidden by a moderator",
+		})
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"body":      req.Body,
+			"spoiler":   req.Spoiler,
+			"editedAt":  now,
+			"updatedAt": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("comments").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(comment)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to update comment",
+			Error:   err.Error(),
+		})
+	}
+
+	role, _ := c.Locals("role").(string)
+	prepareComment(comment, userID, role)
+
+	return respond(c, fiber.StatusOK, "Comment updated successfully", "comment", comment)
+}
+
+// DeleteComment deletes the current user's comment. A comment with replies
+// is only blanked, so its thread stays readable.
+func (cc *CommentsController) DeleteComment(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	comment, err := findOwnComment(ctx, objID, c.Locals("userID").(string))
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch comment")
+	}
+
+	commentsCollection := database.DB.Collection("comments")
+
+	// The replyCount filter keeps a reply that arrives meanwhile attached
+	result, err := commentsCollection.DeleteOne(ctx, bson.M{"_id": objID, "replyCount": 0})
+	if err == nil && result.DeletedCount == 0 {
+		_, err = commentsCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+			"$set": bson.M{"deleted": true, "body": "", "spoiler": false, "updatedAt": time.Now()},
+		})
+	} else if err == nil && comment.ParentID != nil {
+		_, err = commentsCollection.UpdateOne(ctx, bson.M{"_id": *comment.ParentID}, bson.M{"$inc": bson.M{"replyCount": -1}})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete comment",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Comment deleted successfully", "", nil)
+}
+
+// AddReaction adds one of the current user's reactions to a comment
+func (cc *CommentsController) AddReaction(c *fiber.Ctx) error {
+	return cc.setReaction(c, true)
+}
+
+// RemoveReaction withdraws one of the current user's reactions to a comment
+func (cc *CommentsController) RemoveReaction(c *fiber.Ctx) error {
+	return cc.setReaction(c, false)
+}
+
+func (cc *CommentsController) setReaction(c *fiber.Ctx, add bool) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	reaction := c.Params("reaction")
+	if !models.IsCommentReaction(reaction) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid reaction. Must be: " + strings.Join(models.CommentReactions, ", "),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	commentsCollection := database.DB.Collection("comments")
+	userID := c.Locals("userID").(string)
+
+	var comment models.Comment
+	err = commentsCollection.FindOne(ctx, bson.M{"_id": objID, "deleted": false, "hidden": false}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Comment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch comment",
+			Error:   err.Error(),
+		})
+	}
+
+	// The filter on the reacting users keeps the counts in step with them
+	// when the same reaction arrives twice
+	users := "reactions." + reaction
+	counts := bson.M{"reactionCounts." + reaction: 1, "reactionCount": 1}
+	filter := bson.M{"_id": objID, users: bson.M{"$ne": userID}}
+	update := bson.M{"$addToSet": bson.M{users: userID}, "$inc": counts}
+	if !add {
+		counts = bson.M{"reactionCounts." + reaction: -1, "reactionCount": -1}
+		filter[users] = userID
+		update = bson.M{"$pull": bson.M{users: userID}, "$inc": counts}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = commentsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&comment)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to record reaction",
+			Error:   err.Error(),
+		})
+	}
+
+	role, _ := c.Locals("role").(string)
+	prepareComment(&comment, userID, role)
+
+	return respond(c, fiber.StatusOK, "Reaction recorded successfully", "comment", comment)
+}
+
+// ModerateComment hides, locks or pins a comment as a moderator. Only
+// top-level comments can be locked or pinned.
+func (cc *CommentsController) ModerateComment(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid comment ID",
+		})
+	}
+
+	var req models.ModerateCommentRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	comment, err := findComment(ctx, objID)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch comment")
+	}
+
+	if comment.Depth > 0 && (req.Locked != nil || req.Pinned != nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Only top-level comments can be locked or pinned",
+		})
+	}
+
+	userID := c.Locals("userID").(string)
+	set := bson.M{}
+	update := bson.M{"$set": set}
+	if req.Hidden != nil {
+		set["hidden"] = *req.Hidden
+		if *req.Hidden {
+			set["hiddenBy"] = userID
+			set["hideReason"] = req.Reason
+		} else {
+			update["$unset"] = bson.M{"hiddenBy": "", "hideReason": ""}
+		}
+	}
+	if req.Locked != nil {
+		set["locked"] = *req.Locked
+	}
+	if req.Pinned != nil {
+		set["pinned"] = *req.Pinned
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("comments").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(comment)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to moderate comment",
+			Error:   err.Error(),
+		})
+	}
+
+	role, _ := c.Locals("role").(string)
+	prepareComment(comment, userID, role)
+
+	return respond(c, fiber.StatusOK, "Comment moderated successfully", "comment", comment)
+}
+
+// listComments responds with a page of the comments matching filter
+func (cc *CommentsController) listComments(c *fiber.Ctx, ctx context.Context, filter bson.M, sort bson.D) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limitNum < 1 || limitNum > 50 {
+		limitNum = 20
+	}
+
+	commentsCollection := database.DB.Collection("comments")
+
+	total, err := commentsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count comments",
+			Error:   err.Error(),
+		})
+	}
+
+	opts := options.Find().
+		SetSkip(int64((pageNum - 1) * limitNum)).
+		SetLimit(int64(limitNum)).
+		SetSort(sort)
+	cursor, err := commentsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch comments",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var comments []models.Comment
+	if err = cursor.All(ctx, &comments); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse comments",
+			Error:   err.Error(),
+		})
+	}
+
+	userID, _ := c.Locals("userID").(string)
+	role, _ := c.Locals("role").(string)
+	for i := range comments {
+		prepareComment(&comments[i], userID, role)
+	}
+
+	if comments == nil {
+		comments = []models.Comment{}
+	}
+
+	return respondPage(c, "Comments retrieved successfully", "comments", comments, pageMeta(total, pageNum, limitNum))
+}
+
+// insertComment stores a new comment by the current user and pushes it to
+// the clients viewing its page. Replies also count on their parent.
+func (cc *CommentsController) insertComment(c *fiber.Ctx, ctx context.Context, comment *models.Comment, req *models.CommentRequest, public bool) error {
+	userID := c.Locals("userID").(string)
+	userObjID, _ := primitive.ObjectIDFromHex(userID)
+	var author models.User
+	if err := database.DB.Collection("users").FindOne(ctx, bson.M{"_id": userObjID}).Decode(&author); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch user",
+			Error:   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	comment.UserID = userID
+	comment.AuthorName = author.DisplayName
+	comment.Body = req.Body
+	comment.Spoiler = req.Spoiler
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	// Count the reply on its parent first: a parent without replies can be
+	// deleted outright, so the count keeps it in place for the new reply
+	commentsCollection := database.DB.Collection("comments")
+	if comment.ParentID != nil {
+		result, err := commentsCollection.UpdateOne(ctx,
+			bson.M{"_id": *comment.ParentID, "deleted": false},
+			bson.M{"$inc": bson.M{"replyCount": 1}},
+		)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Failed to create comment",
+				Error:   err.Error(),
+			})
+		}
+		if result.MatchedCount == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Comment not found",
+			})
+		}
+	}
+
+	result, err := commentsCollection.InsertOne(ctx, comment)
+	if err != nil {
+		if comment.ParentID != nil {
+			if _, err := commentsCollection.UpdateOne(ctx, bson.M{"_id": *comment.ParentID}, bson.M{"$inc": bson.M{"replyCount": -1}}); err != nil {
+				fmt.Printf("Warning: Failed to uncount reply on comment %s: %v\n", comment.ParentID.Hex(), err)
+			}
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to create comment",
+			Error:   err.Error(),
+		})
+	}
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+
+	prepareComment(comment, "", "")
+	if public {
+		handlers.BroadcastToRoom(commentRoom(comment), "comment_created", comment)
+	}
+
+	return respond(c, fiber.StatusCreated, "Comment created successfully", "comment", comment)
+}
+
+// commentSort returns the order of top-level comments for a sort name.
+// Pinned comments come first in either order.
+func commentSort(name string) (bson.D, bool) {
+	sort := bson.D{{Key: "pinned", Value: -1}}
+	switch name {
+	case models.CommentSortTop:
+		sort = append(sort, bson.E{Key: "reactionCount", Value: -1})
+	case models.CommentSortNewest:
+	default:
+		return nil, false
+	}
+	return append(sort, bson.E{Key: "createdAt", Value: -1}, bson.E{Key: "_id", Value: -1}), true
+}
+
+// prepareComment sets the current user's reactions on a comment and blanks
+// deleted comments, and hidden ones for non-moderators
+func prepareComment(comment *models.Comment, userID string, role string) {
+	if userID != "" {
+		for _, reaction := range models.CommentReactions {
+			for _, id := range comment.Reactions[reaction] {
+				if id == userID {
+					comment.MyReactions = append(comment.MyReactions, reaction)
+					break
+				}
+			}
+		}
+	}
+	if comment.ReactionCounts == nil {
+		comment.ReactionCounts = map[string]int{}
+	}
+
+	moderator := models.HasPermission(role, models.PermModerateComments)
+	if comment.Deleted || (comment.Hidden && !moderator) {
+		comment.Body = ""
+	}
+	if !moderator {
+		comment.HiddenBy = ""
+	}
+}
+
+// commentRoom is the websocket room of the page a comment is on
+func commentRoom(comment *models.Comment) string {
+	if comment.EpisodeID != nil {
+		return "episode:" + comment.EpisodeID.Hex()
+	}
+	return "anime:" + comment.AnimeID
+}
+
+// parseCommentRequest reads and validates the body of a comment request
+func parseCommentRequest(c *fiber.Ctx) (*models.CommentRequest, error) {
+	var req models.CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if msg := req.Validate(); msg != "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, msg)
+	}
+	return &req, nil
+}
+
+// findVisibleEpisode loads an episode the role may see
+func findVisibleEpisode(ctx context.Context, objID primitive.ObjectID, role string) (*models.Episode, error) {
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+	if !episodeVisibleTo(role, episode) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	return episode, nil
+}
+
+func findComment(ctx context.Context, objID primitive.ObjectID) (*models.Comment, error) {
+	var comment models.Comment
+	err := database.DB.Collection("comments").FindOne(ctx, bson.M{"_id": objID}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Comment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func findOwnComment(ctx context.Context, objID primitive.ObjectID, userID string) (*models.Comment, error) {
+	var comment models.Comment
+	err := database.DB.Collection("comments").FindOne(ctx, bson.M{"_id": objID, "userId": userID, "deleted": false}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Comment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
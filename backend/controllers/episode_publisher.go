@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// publishInterval is how often scheduled episodes are checked for release
+const publishInterval = time.Minute
+
+// StartEpisodePublisher publishes scheduled episodes once their publicAt
+// time passes and refreshes the episode statistics of their anime
+func StartEpisodePublisher(cfg *config.Config) {
+	go func() {
+		ticker := time.NewTicker(publishInterval)
+		defer ticker.Stop()
+
+		for {
+			publishDueEpisodes(cfg.AutoAnimeStatus)
+			<-ticker.C
+		}
+	}()
+}
+
+func publishDueEpisodes(autoStatus bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"published": false, "publicAt": bson.M{"$lte": time.Now()}}
+	opts := options.Find().SetProjection(bson.M{"animeId": 1, "type": 1, "season": 1, "number": 1})
+	cursor, err := database.DB.Collection("episodes").Find(ctx, filter, opts)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find scheduled episodes: %v\n", err)
+		return
+	}
+	var episodes []models.Episode
+	if err = cursor.All(ctx, &episodes); err != nil {
+		fmt.Printf("Warning: Failed to find scheduled episodes: %v\n", err)
+		return
+	}
+	if len(episodes) == 0 {
+		return
+	}
+
+	ids := []primitive.ObjectID{}
+	animeIDs := []string{}
+	for _, episode := range episodes {
+		ids = append(ids, episode.ID)
+		animeIDs = append(animeIDs, episode.AnimeID)
+	}
+
+	// Publish only the episodes found above, so each one is announced
+	filter["_id"] = bson.M{"$in": ids}
+	result, err := database.DB.Collection("episodes").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"published": true}})
+	if err != nil {
+		fmt.Printf("Warning: Failed to publish scheduled episodes: %v\n", err)
+		return
+	}
+	fmt.Printf("✓ Published %d scheduled episodes\n", result.ModifiedCount)
+
+	refreshEpisodeStats(ctx, autoStatus, animeIDs...)
+	for i := range episodes {
+		notifyEpisodeReleased(&episodes[i])
+	}
+}
+
+// episodeVisibility returns the filter limiting episodes to those a role may
+// see: editors see every episode, early access roles also see episodes past
+// their vipAt time, everyone else only published episodes.
+func episodeVisibility(role string) bson.M {
+	if models.HasPermission(role, models.PermEditAnime) {
+		return bson.M{}
+	}
+
+	public := bson.M{"published": true}
+	if models.HasPermission(role, models.PermEarlyAccess) {
+		return bson.M{"$or": bson.A{public, bson.M{"vipAt": bson.M{"$lte": time.Now()}}}}
+	}
+	return public
+}
+
+// withEpisodeVisibility narrows an episode filter to what a role may see
+func withEpisodeVisibility(filter bson.M, role string) bson.M {
+	visibility := episodeVisibility(role)
+	if len(visibility) == 0 {
+		return filter
+	}
+	if len(filter) == 0 {
+		return visibility
+	}
+	return bson.M{"$and": bson.A{filter, visibility}}
+}
+
+// episodeVisibleTo reports whether a role may see an episode; it matches
+// episodeVisibility
+func episodeVisibleTo(role string, episode *models.Episode) bool {
+	if models.HasPermission(role, models.PermEditAnime) || episode.Published {
+		return true
+	}
+	return models.HasPermission(role, models.PermEarlyAccess) && episode.VipAt != nil && !episode.VipAt.After(time.Now())
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// syncEpisodeStats recomputes the episode statistics stored on an anime from
+// its released episodes. Published episodes are regular, whole-numbered,
+// non-recap episodes, so they compare with the expected EpisodeCount. With autoStatus
+// the status follows the published episodes as described by nextStatus.
+func syncEpisodeStats(ctx context.Context, animeID string, autoStatus bool) error {
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return err
+	}
+
+	var anime models.Anime
+	if err := database.DB.Collection("anime").FindOne(ctx, bson.M{"_id": objID}).Decode(&anime); err != nil {
+		return err
+	}
+
+	opts := options.Find().
+		SetSort(models.EpisodeSort).
+		SetProjection(bson.M{"type": 1, "season": 1, "number": 1, "isRecap": 1, "publicAt": 1})
+	filter := withEpisodeVisibility(bson.M{"animeId": animeID}, "")
+	cursor, err := database.DB.Collection("episodes").Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var episodes []models.Episode
+	if err = cursor.All(ctx, &episodes); err != nil {
+		return err
+	}
+
+	published := 0
+	latest := 0.0
+	var lastEpisodeAt *time.Time
+	for i := range episodes {
+		episode := &episodes[i]
+		if lastEpisodeAt == nil || episode.PublicAt.After(*lastEpisodeAt) {
+			lastEpisodeAt = &episode.PublicAt
+		}
+		if episode.Type == models.EpisodeTypeSpecial {
+			continue
+		}
+		// Episodes are in display order, so the last regular one is the latest
+		latest = episode.Number
+		if !episode.IsRecap && episode.Number == math.Trunc(episode.Number) {
+			published++
+		}
+	}
+
+	set := bson.M{
+		"publishedEpisodes": published,
+		"latestEpisode":     latest,
+		"updatedAt":         time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if lastEpisodeAt != nil {
+		set["lastEpisodeAt"] = *lastEpisodeAt
+	} else {
+		update["$unset"] = bson.M{"lastEpisodeAt": ""}
+	}
+
+	if autoStatus {
+		if status := nextStatus(anime.Status, anime.AutoStatus, published, anime.EpisodeCount); status != anime.Status {
+			set["status"] = status
+			set["autoStatus"] = true
+		}
+	}
+
+	if _, err = database.DB.Collection("anime").UpdateOne(ctx, bson.M{"_id": objID}, update); err != nil {
+		return err
+	}
+
+	if status, ok := set["status"].(string); ok && status != anime.Status {
+		notifyStatusChanged(animeID, anime.Title, status)
+	}
+	return nil
+}
+
+// nextStatus returns the status an anime moves to with the given number of
+// published episodes out of the expected count (0 if unknown). Statuses only
+// move forward, upcoming to ongoing to completed, except that a status set
+// automatically goes back when episodes are unpublished or deleted. Other
+// statuses, and any set by an editor when going back, are kept.
+func nextStatus(status string, auto bool, published, expected int) string {
+	complete := expected > 0 && published >= expected
+
+	switch status {
+	case "upcoming":
+		if published == 0 {
+			return status
+		}
+	case "ongoing":
+		if published == 0 && auto {
+			return "upcoming"
+		}
+	case "completed":
+		if complete || !auto {
+			return status
+		}
+		if published == 0 {
+			return "upcoming"
+		}
+		return "ongoing"
+	default:
+		return status
+	}
+
+	if complete {
+		return "completed"
+	}
+	return "ongoing"
+}
+
+// refreshEpisodeStats runs syncEpisodeStats for each anime whose episodes
+// changed. The episode write already succeeded, so failures are only logged.
+func refreshEpisodeStats(ctx context.Context, autoStatus bool, animeIDs ...string) {
+	seen := map[string]bool{}
+	for _, animeID := range animeIDs {
+		if animeID == "" || seen[animeID] {
+			continue
+		}
+		seen[animeID] = true
+		if err := syncEpisodeStats(ctx, animeID, autoStatus); err != nil {
+			fmt.Printf("Warning: Failed to sync episode stats for anime %s: %v\n", animeID, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import "testing"
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		auto      bool
+		published int
+		expected  int
+		want      string
+	}{
+		{"upcoming without episodes", "upcoming", false, 0, 12, "upcoming"},
+		{"upcoming first episode", "upcoming", false, 1, 12, "ongoing"},
+		{"upcoming released at once", "upcoming", false, 12, 12, "completed"},
+		{"upcoming unknown count", "upcoming", false, 30, 0, "ongoing"},
+		{"ongoing last episode", "ongoing", false, 12, 12, "completed"},
+		{"ongoing more to come", "ongoing", true, 5, 12, "ongoing"},
+		{"auto ongoing episodes deleted", "ongoing", true, 0, 12, "upcoming"},
+		{"editor ongoing episodes deleted", "ongoing", false, 0, 12, "ongoing"},
+		{"auto completed episode deleted", "completed", true, 11, 12, "ongoing"},
+		{"auto completed all deleted", "completed", true, 0, 12, "upcoming"},
+		{"editor completed with missing episodes", "completed", false, 3, 12, "completed"},
+		{"auto completed count raised", "completed", true, 12, 24, "ongoing"},
+		{"unknown status kept", "hiatus", true, 12, 12, "hiatus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextStatus(tt.status, tt.auto, tt.published, tt.expected); got != tt.want {
+				t.Errorf("nextStatus(%q, %v, %d, %d) = %q, want %q", tt.status, tt.auto, tt.published, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
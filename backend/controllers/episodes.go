@@ -2,21 +2,45 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
 	"toofy-backend/database"
 	"toofy-backend/models"
+	"toofy-backend/utils"
 )
 
-type EpisodesController struct{}
+type EpisodesController struct {
+	embedHosts []string
+	autoStatus bool
+	baseURL    string
+	signer     *utils.MediaSigner
+	// markerThreshold is the vote score at which a user submitted skip
+	// marker is added to its episode
+	markerThreshold int
+}
+
+func NewEpisodesController(cfg *config.Config) *EpisodesController {
+	return &EpisodesController{
+		embedHosts: cfg.EmbedHosts,
+		autoStatus: cfg.AutoAnimeStatus,
+		baseURL:    cfg.BaseURL,
+		signer:     utils.NewMediaSigner(cfg.MediaSigningKey, cfg.MediaURLTTL, cfg.MediaBindIP),
 
-func NewEpisodesController() *EpisodesController {
-	return &EpisodesController{}
+		markerThreshold: cfg.MarkerVoteThreshold,
+	}
 }
 
-// GetEpisodesByAnimeID returns episodes for a specific anime
+// GetEpisodesByAnimeID returns episodes for a specific anime in display
+// order, optionally filtered by season and type. The list is paginated when
+// page or limit is given; otherwise every episode is returned.
 func (ec *EpisodesController) GetEpisodesByAnimeID(c *fiber.Ctx) error {
 	animeID := c.Query("animeId")
 
@@ -27,13 +51,54 @@ func (ec *EpisodesController) GetEpisodesByAnimeID(c *fiber.Ctx) error {
 		})
 	}
 
+	filter := bson.M{"animeId": animeID}
+	if season := c.Query("season"); season != "" {
+		seasonNum, err := strconv.Atoi(season)
+		if err != nil || seasonNum < 1 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "season must be a positive number",
+			})
+		}
+		filter["season"] = seasonNum
+	}
+	switch episodeType := c.Query("type"); episodeType {
+	case "":
+	case models.EpisodeTypeRegular:
+		filter["type"] = models.EpisodeTypeRegular
+	case models.EpisodeTypeSpecial:
+		filter["type"] = models.EpisodeTypeSpecial
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode type. Must be: regular or special",
+		})
+	}
+
+	paginated := c.Query("page") != "" || c.Query("limit") != ""
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limitNum < 1 || limitNum > 100 {
+		limitNum = 50
+	}
+
+	role, _ := c.Locals("role").(string)
+	filter = withEpisodeVisibility(filter, role)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	episodesCollection := database.DB.Collection("episodes")
 
 	// Find episodes for the anime
-	cursor, err := episodesCollection.Find(ctx, bson.M{"animeId": animeID})
+	opts := options.Find().SetSort(models.EpisodeSort)
+	if paginated {
+		opts.SetSkip(int64((pageNum - 1) * limitNum)).SetLimit(int64(limitNum))
+	}
+	cursor, err := episodesCollection.Find(ctx, filter, opts)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Success: false,
@@ -43,7 +108,7 @@ func (ec *EpisodesController) GetEpisodesByAnimeID(c *fiber.Ctx) error {
 	}
 	defer cursor.Close(ctx)
 
-	var episodes []interface{}
+	var episodes []models.Episode
 	if err = cursor.All(ctx, &episodes); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Success: false,
@@ -53,12 +118,706 @@ func (ec *EpisodesController) GetEpisodesByAnimeID(c *fiber.Ctx) error {
 	}
 
 	if episodes == nil {
-		episodes = []interface{}{}
+		episodes = []models.Episode{}
+	}
+	for i := range episodes {
+		ec.prepareEpisode(c, &episodes[i])
+	}
+
+	if !paginated {
+		return respond(c, fiber.StatusOK, "Episodes retrieved successfully", "", episodes)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Episodes retrieved successfully",
-		"data":    episodes,
+	total, err := episodesCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count episodes",
+			Error:   err.Error(),
+		})
+	}
+
+	return respondPage(c, "Episodes retrieved successfully", "data", episodes, pageMeta(total, pageNum, limitNum))
+}
+
+// GetEpisodeByID returns a single episode with links to the previous and
+// next episodes of the same anime
+func (ec *EpisodesController) GetEpisodeByID(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Episodes not yet released to the caller's role are reported missing
+	role, _ := c.Locals("role").(string)
+	var episode models.Episode
+	err = database.DB.Collection("episodes").FindOne(ctx, bson.M{"_id": objID}).Decode(&episode)
+	if err != nil || !episodeVisibleTo(role, &episode) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode not found",
+		})
+	}
+	ec.prepareEpisode(c, &episode)
+
+	navigation, err := episodeNavigation(ctx, &episode, role, episodesPath(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch episode navigation",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Episode retrieved successfully", "", fiber.Map{
+		"episode":    episode,
+		"navigation": navigation,
 	})
 }
+
+// episodeNavigation finds the episodes around an episode in display order,
+// among those visible to role. basePath is the episode route prefix the
+// links are built on.
+func episodeNavigation(ctx context.Context, episode *models.Episode, role string, basePath string) (models.EpisodeNavigation, error) {
+	navigation := models.EpisodeNavigation{}
+
+	opts := options.Find().
+		SetSort(models.EpisodeSort).
+		SetProjection(bson.M{"type": 1, "season": 1, "number": 1, "title": 1})
+	filter := withEpisodeVisibility(bson.M{"animeId": episode.AnimeID}, role)
+	cursor, err := database.DB.Collection("episodes").Find(ctx, filter, opts)
+	if err != nil {
+		return navigation, err
+	}
+	defer cursor.Close(ctx)
+
+	var siblings []models.Episode
+	if err = cursor.All(ctx, &siblings); err != nil {
+		return navigation, err
+	}
+
+	ref := func(sibling models.Episode) *models.EpisodeRef {
+		normalizeEpisode(&sibling)
+		return &models.EpisodeRef{
+			ID:    sibling.ID,
+			Label: sibling.Label,
+			Title: sibling.Title,
+			Href:  basePath + sibling.ID.Hex(),
+		}
+	}
+
+	for i, sibling := range siblings {
+		if sibling.ID != episode.ID {
+			continue
+		}
+		if i > 0 {
+			navigation.Previous = ref(siblings[i-1])
+		}
+		if i+1 < len(siblings) {
+			navigation.Next = ref(siblings[i+1])
+		}
+		break
+	}
+	return navigation, nil
+}
+
+// CreateEpisode adds an episode to an existing anime
+func (ec *EpisodesController) CreateEpisode(c *fiber.Ctx) error {
+	var req models.EpisodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ensureAnimeExists(ctx, req.AnimeID); err != nil {
+		return episodeErrorResponse(c, err, "Failed to create episode")
+	}
+
+	episode := req.ToEpisode()
+	if msg := vipAtError(&episode); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+	episode.Sources = []models.VideoSource{}
+	episode.Subtitles = []models.SubtitleTrack{}
+	episode.Markers = []models.SkipMarker{}
+	episode.CreatedAt = time.Now()
+	episode.UpdatedAt = time.Now()
+
+	result, err := database.DB.Collection("episodes").InsertOne(ctx, episode)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to create episode")
+	}
+	episode.ID = result.InsertedID.(primitive.ObjectID)
+
+	refreshEpisodeStats(ctx, ec.autoStatus, episode.AnimeID)
+	if episode.Published {
+		notifyEpisodeReleased(&episode)
+	}
+
+	return respond(c, fiber.StatusCreated, "Episode created successfully", "episode", episode)
+}
+
+// UpdateEpisode replaces the editable fields of an episode
+func (ec *EpisodesController) UpdateEpisode(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.EpisodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ensureAnimeExists(ctx, req.AnimeID); err != nil {
+		return episodeErrorResponse(c, err, "Failed to update episode")
+	}
+
+	// The previous anime needs its statistics refreshed if the episode moves
+	current, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to update episode")
+	}
+
+	episode := req.ToEpisode()
+	if req.PublicAt == nil {
+		episode.PublicAt = current.PublicAt
+		episode.Published = !episode.PublicAt.After(time.Now())
+	}
+	if msg := vipAtError(&episode); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"animeId":        episode.AnimeID,
+			"type":           episode.Type,
+			"season":         episode.Season,
+			"number":         episode.Number,
+			"absoluteNumber": episode.AbsoluteNumber,
+			"title":          episode.Title,
+			"synopsis":       episode.Synopsis,
+			"airDate":        episode.AirDate,
+			"duration":       episode.Duration,
+			"thumbnailUrl":   episode.ThumbnailUrl,
+			"isFiller":       episode.IsFiller,
+			"isRecap":        episode.IsRecap,
+			"publicAt":       episode.PublicAt,
+			"vipAt":          episode.VipAt,
+			"published":      episode.Published,
+			"updatedAt":      time.Now(),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("episodes").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode not found",
+		})
+	}
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to update episode")
+	}
+
+	refreshEpisodeStats(ctx, ec.autoStatus, current.AnimeID, episode.AnimeID)
+	if !episodeVisibleTo("", current) && episode.Published {
+		notifyEpisodeReleased(&episode)
+	}
+
+	if current.AnimeID != episode.AnimeID {
+		if _, err := database.DB.Collection("watch_progress").UpdateMany(ctx, bson.M{"episodeId": objID}, bson.M{"$set": bson.M{"animeId": episode.AnimeID}}); err != nil {
+			fmt.Printf("Warning: Failed to move watch progress of episode %s: %v\n", objID.Hex(), err)
+		}
+		if _, err := database.DB.Collection("comments").UpdateMany(ctx, bson.M{"episodeId": objID}, bson.M{"$set": bson.M{"animeId": episode.AnimeID}}); err != nil {
+			fmt.Printf("Warning: Failed to move comments of episode %s: %v\n", objID.Hex(), err)
+		}
+	}
+
+	return respond(c, fiber.StatusOK, "Episode updated successfully", "episode", episode)
+}
+
+// vipAtError returns an error message if an episode's early access release
+// is not before its public release
+func vipAtError(episode *models.Episode) string {
+	if episode.VipAt != nil && !episode.VipAt.Before(episode.PublicAt) {
+		return "vipAt must be before publicAt"
+	}
+	return ""
+}
+
+// DeleteEpisode deletes an episode
+func (ec *EpisodesController) DeleteEpisode(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var episode models.Episode
+	err = database.DB.Collection("episodes").FindOneAndDelete(ctx, bson.M{"_id": objID}).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete episode",
+			Error:   err.Error(),
+		})
+	}
+
+	refreshEpisodeStats(ctx, ec.autoStatus, episode.AnimeID)
+
+	if _, err := database.DB.Collection("marker_submissions").DeleteMany(ctx, bson.M{"episodeId": objID}); err != nil {
+		fmt.Printf("Warning: Failed to delete marker submissions of episode %s: %v\n", objID.Hex(), err)
+	}
+	if _, err := database.DB.Collection("source_reports").DeleteMany(ctx, bson.M{"episodeId": objID}); err != nil {
+		fmt.Printf("Warning: Failed to delete source reports of episode %s: %v\n", objID.Hex(), err)
+	}
+	if _, err := database.DB.Collection("watch_progress").DeleteMany(ctx, bson.M{"episodeId": objID}); err != nil {
+		fmt.Printf("Warning: Failed to delete watch progress of episode %s: %v\n", objID.Hex(), err)
+	}
+	if _, err := database.DB.Collection("comments").DeleteMany(ctx, bson.M{"episodeId": objID}); err != nil {
+		fmt.Printf("Warning: Failed to delete comments of episode %s: %v\n", objID.Hex(), err)
+	}
+
+	return respond(c, fiber.StatusOK, "Episode deleted successfully", "", nil)
+}
+
+// ReplaceSources replaces the playback sources of an episode
+func (ec *EpisodesController) ReplaceSources(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.SourcesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	sources := req.Sources
+	if sources == nil {
+		sources = []models.VideoSource{}
+	}
+	seen := map[string]bool{}
+	for i := range sources {
+		// Hosted sources come back signed from GetEpisodeByID
+		sources[i].URL = utils.StripMediaSignature(sources[i].URL)
+		if msg := sources[i].Validate(ec.embedHosts); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: fmt.Sprintf("Source %d: %s", i+1, msg),
+			})
+		}
+		if sources[i].ID == "" {
+			sources[i].ID = primitive.NewObjectID().Hex()
+		}
+		if seen[sources[i].ID] {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Duplicate source ID " + sources[i].ID,
+			})
+		}
+		seen[sources[i].ID] = true
+	}
+	models.SortSources(sources)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	current, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to update sources")
+	}
+
+	if err := saveSources(ctx, objID, sources); err != nil {
+		return episodeErrorResponse(c, err, "Failed to update sources")
+	}
+	if err := respond(c, fiber.StatusOK, "Sources updated successfully", "sources", sources); err != nil {
+		return err
+	}
+
+	previous := current.Sources
+	current.Sources = sources
+	notifySourcesAdded(current, previous)
+	return nil
+}
+
+// DownloadSource returns a signed download URL for a hosted MP4 source
+func (ec *EpisodesController) DownloadSource(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+	if role, _ := c.Locals("role").(string); !episodeVisibleTo(role, episode) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode not found",
+		})
+	}
+
+	var source *models.VideoSource
+	for i := range episode.Sources {
+		if episode.Sources[i].ID == c.Params("sourceId") && !episode.Sources[i].Disabled {
+			source = &episode.Sources[i]
+			break
+		}
+	}
+	if source == nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Source not found",
+		})
+	}
+	if source.Type != models.SourceTypeMP4 || !isHostedMedia(source.URL, ec.baseURL) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Source is not available for download",
+		})
+	}
+
+	userID, _ := c.Locals("userID").(string)
+	url, expiresAt, err := ec.signer.Sign(source.URL, userID, c.IP(), true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to sign download URL",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Download URL created successfully", "", fiber.Map{
+		"url":       url,
+		"expiresAt": expiresAt,
+	})
+}
+
+// ReorderSources sets the playback order of an episode's sources. Sources
+// missing from the list keep their relative order after the listed ones.
+func (ec *EpisodesController) ReorderSources(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.ReorderSourcesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	position := map[string]int{}
+	for i, id := range req.SourceIDs {
+		position[id] = i
+	}
+	for _, id := range req.SourceIDs {
+		if !hasSource(episode.Sources, id) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Unknown source ID " + id,
+			})
+		}
+	}
+
+	// Only the priorities are written, so a source disabled by reports or
+	// added meanwhile keeps its other fields
+	models.SortSources(episode.Sources)
+	next := len(req.SourceIDs)
+	branches := bson.A{}
+	for _, source := range episode.Sources {
+		priority, ok := position[source.ID]
+		if !ok {
+			priority = next
+			next++
+		}
+		branches = append(branches, bson.M{"case": bson.M{"$eq": bson.A{"$$this.id", source.ID}}, "then": priority})
+	}
+	if len(branches) == 0 {
+		return respond(c, fiber.StatusOK, "Sources reordered successfully", "sources", episode.Sources)
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"sources": bson.M{"$map": bson.M{
+				"input": "$sources",
+				"in": bson.M{"$mergeObjects": bson.A{
+					"$$this",
+					bson.M{"priority": bson.M{"$switch": bson.M{"branches": branches, "default": "$$this.priority"}}},
+				}},
+			}},
+			"updatedAt": time.Now(),
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("episodes").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(episode)
+	if err == mongo.ErrNoDocuments {
+		err = fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to update sources")
+	}
+	models.SortSources(episode.Sources)
+
+	return respond(c, fiber.StatusOK, "Sources reordered successfully", "sources", episode.Sources)
+}
+
+// UpdateSourceStatus enables or disables a single source, e.g. when its
+// host is down
+func (ec *EpisodesController) UpdateSourceStatus(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.SourceStatusRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": objID, "sources.id": c.Params("sourceId")}
+	update := bson.M{
+		"$set": bson.M{
+			"sources.$.disabled": req.Disabled,
+			"updatedAt":          time.Now(),
+		},
+	}
+
+	var episode models.Episode
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("episodes").FindOneAndUpdate(ctx, filter, update, opts).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode or source not found",
+		})
+	}
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to update source")
+	}
+	models.SortSources(episode.Sources)
+
+	return respond(c, fiber.StatusOK, "Source updated successfully", "sources", episode.Sources)
+}
+
+// saveSources replaces the sources of an episode
+func saveSources(ctx context.Context, objID primitive.ObjectID, sources []models.VideoSource) error {
+	update := bson.M{
+		"$set": bson.M{
+			"sources":   sources,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := database.DB.Collection("episodes").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	return nil
+}
+
+// normalizeEpisode sets the display label of an episode read from the
+// database
+func normalizeEpisode(episode *models.Episode) {
+	episode.Label = models.EpisodeLabel(episode)
+}
+
+// prepareEpisode orders an episode's sources and subtitles for the client.
+// Only users who can edit anime see disabled sources. Hosted sources get URLs
+// signed for the requesting user.
+func (ec *EpisodesController) prepareEpisode(c *fiber.Ctx, episode *models.Episode) {
+	normalizeEpisode(episode)
+	models.SortSubtitles(episode.Subtitles)
+	if episode.Subtitles == nil {
+		episode.Subtitles = []models.SubtitleTrack{}
+	}
+	models.SortMarkers(episode.Markers)
+	if episode.Markers == nil {
+		episode.Markers = []models.SkipMarker{}
+	}
+
+	role, _ := c.Locals("role").(string)
+	if models.HasPermission(role, models.PermEditAnime) {
+		if episode.Sources == nil {
+			episode.Sources = []models.VideoSource{}
+		}
+		models.SortSources(episode.Sources)
+	} else {
+		episode.Sources = models.PlayableSources(episode.Sources)
+	}
+
+	userID, _ := c.Locals("userID").(string)
+	episode.Sources = signSources(ec.signer, ec.baseURL, episode.Sources, userID, c.IP())
+}
+
+// signSources replaces the URLs of hosted sources with signed playback URLs.
+// Without a user, hosted sources are left out as they cannot be signed.
+func signSources(signer *utils.MediaSigner, baseURL string, sources []models.VideoSource, userID string, ip string) []models.VideoSource {
+	signed := []models.VideoSource{}
+	for _, source := range sources {
+		if isHostedMedia(source.URL, baseURL) {
+			if userID == "" {
+				continue
+			}
+			url, _, err := signer.Sign(source.URL, userID, ip, false)
+			if err != nil {
+				continue
+			}
+			source.URL = url
+		}
+		signed = append(signed, source)
+	}
+	return signed
+}
+
+// findEpisode loads an episode by ID
+func findEpisode(ctx context.Context, objID primitive.ObjectID) (*models.Episode, error) {
+	var episode models.Episode
+	err := database.DB.Collection("episodes").FindOne(ctx, bson.M{"_id": objID}).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &episode, nil
+}
+
+func hasSource(sources []models.VideoSource, id string) bool {
+	for _, source := range sources {
+		if source.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureAnimeExists checks that an episode references an existing anime
+func ensureAnimeExists(ctx context.Context, animeID string) error {
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid anime ID")
+	}
+
+	count, err := database.DB.Collection("anime").CountDocuments(ctx, bson.M{"_id": objID}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Anime not found")
+	}
+	return nil
+}
+
+// episodeErrorResponse maps validation and storage errors of episode writes
+// to responses; the unique (animeId, type, season, number) index reports duplicates
+func episodeErrorResponse(c *fiber.Ctx, err error, message string) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "An episode with this number already exists in this season",
+		})
+	}
+	return errorResponse(c, err, message)
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+const (
+	// latestBatchWindow is how far apart episodes of one anime may be
+	// released and still share a card
+	latestBatchWindow = 6 * time.Hour
+	// latestChunkSize is the number of episodes read from the database at a time
+	latestChunkSize = 100
+	// latestMaxChunks bounds the episodes read for one page
+	latestMaxChunks = 10
+)
+
+// GetLatestEpisodes returns the newest released episodes across the catalog
+// as cards. Consecutive releases of one anime within latestBatchWindow share
+// a card, so a batch upload shows once. Pages continue from the cursor of the
+// previous page.
+func (ec *EpisodesController) GetLatestEpisodes(c *fiber.Ctx) error {
+	limitNum, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limitNum < 1 || limitNum > 50 {
+		limitNum = 20
+	}
+
+	// The rail is public, so only episodes released to everyone are listed
+	filter := bson.M{}
+	if cursor := c.Query("cursor"); cursor != "" {
+		publicAt, id, err := decodeLatestCursor(cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid cursor",
+			})
+		}
+		filter = latestAfter(publicAt, id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The listing only needs what goes on a card
+	opts := options.Find().
+		SetSort(bson.D{{Key: "publicAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(latestChunkSize).
+		SetProjection(bson.M{"animeId": 1, "type": 1, "season": 1, "number": 1, "title": 1, "publicAt": 1})
+
+	// batch collects the episodes of one card
+	type batch struct {
+		animeID  string
+		episodes []models.Episode
+	}
+	batches := []*batch{}
+	var last *models.Episode
+	more := false
+
+read:
+	for chunk := 0; ; chunk++ {
+		if chunk == latestMaxChunks {
+			more = true
+			break
+		}
+
+		cursor, err := database.DB.Collection("episodes").Find(ctx, withEpisodeVisibility(filter, ""), opts)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Failed to fetch episodes",
+				Error:   err.Error(),
+			})
+		}
+
+		var episodes []models.Episode
+		err = cursor.All(ctx, &episodes)
+		cursor.Close(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Failed to parse episodes",
+				Error:   err.Error(),
+			})
+		}
+
+		for i := range episodes {
+			episode := episodes[i]
+			if n := len(batches); n > 0 {
+				current := batches[n-1]
+				newest := current.episodes[0].PublicAt
+				if current.animeID == episode.AnimeID && newest.Sub(episode.PublicAt) <= latestBatchWindow {
+					current.episodes = append(current.episodes, episode)
+					last = &episodes[i]
+					continue
+				}
+			}
+			if len(batches) == limitNum {
+				more = true
+				break read
+			}
+			batches = append(batches, &batch{animeID: episode.AnimeID, episodes: []models.Episode{episode}})
+			last = &episodes[i]
+		}
+
+		if len(episodes) < latestChunkSize {
+			break
+		}
+		filter = latestAfter(last.PublicAt, last.ID)
+	}
+
+	animeIDs := []string{}
+	for _, b := range batches {
+		animeIDs = append(animeIDs, b.animeID)
+	}
+	animes, err := findAnimeByHexIDs(ctx, animeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+
+	basePath := episodesPath(c)
+	cards := []models.LatestEpisodesCard{}
+	for _, b := range batches {
+		anime, ok := animes[b.animeID]
+		if !ok {
+			continue
+		}
+
+		item := models.LatestEpisodesCard{
+			Anime:      anime.Ref(),
+			Episodes:   []models.EpisodeRef{},
+			ReleasedAt: b.episodes[0].PublicAt,
+		}
+
+		for i := range b.episodes {
+			normalizeEpisode(&b.episodes[i])
+		}
+		sort.SliceStable(b.episodes, func(i, j int) bool {
+			x, y := b.episodes[i], b.episodes[j]
+			if x.Type != y.Type {
+				return x.Type == models.EpisodeTypeRegular
+			}
+			if x.Season != y.Season {
+				return x.Season < y.Season
+			}
+			return x.Number < y.Number
+		})
+		for _, episode := range b.episodes {
+			item.Episodes = append(item.Episodes, models.EpisodeRef{
+				ID:    episode.ID,
+				Label: episode.Label,
+				Title: episode.Title,
+				Href:  basePath + episode.ID.Hex(),
+			})
+		}
+		cards = append(cards, item)
+	}
+
+	var nextCursor *string
+	if more && last != nil {
+		next := encodeLatestCursor(last.PublicAt, last.ID)
+		nextCursor = &next
+	}
+
+	return respond(c, fiber.StatusOK, "Latest episodes retrieved successfully", "", fiber.Map{
+		"cards":      cards,
+		"nextCursor": nextCursor,
+	})
+}
+
+// latestAfter matches the episodes that come after an episode in the latest
+// episodes order
+func latestAfter(publicAt time.Time, id primitive.ObjectID) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"publicAt": bson.M{"$lt": publicAt}},
+		bson.M{"publicAt": publicAt, "_id": bson.M{"$lt": id}},
+	}}
+}
+
+// encodeLatestCursor builds the opaque cursor of a latest episodes page from
+// the last episode on it
+func encodeLatestCursor(publicAt time.Time, id primitive.ObjectID) string {
+	value := strconv.FormatInt(publicAt.UnixMilli(), 10) + ":" + id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func decodeLatestCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, err
+	}
+
+	millis, hex, found := strings.Cut(string(value), ":")
+	if !found {
+		return time.Time{}, primitive.NilObjectID, errors.New("malformed cursor")
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, err
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, err
+	}
+	return time.UnixMilli(ms), id, nil
+}
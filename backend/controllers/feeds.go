@@ -0,0 +1,424 @@
+package controllers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// feedSize is the number of entries returned by each feed
+const feedSize = 50
+
+type FeedsController struct {
+	siteURL string
+	baseURL string
+}
+
+func NewFeedsController(cfg *config.Config) *FeedsController {
+	return &FeedsController{
+		siteURL: strings.TrimRight(cfg.SiteURL, "/"),
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+	}
+}
+
+// feedItem is a format-neutral feed entry
+type feedItem struct {
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Image       string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// feed is a format-neutral feed rendered as RSS 2.0 or Atom
+type feed struct {
+	Title       string
+	Link        string
+	Self        string
+	Description string
+	Items       []feedItem
+}
+
+func (fc *FeedsController) animeURL(slug string) string {
+	return animePageURL(fc.siteURL, slug)
+}
+
+func (fc *FeedsController) episodeURL(slug string, label string) string {
+	return fmt.Sprintf("%s/anime/%s/episodes/%s", fc.siteURL, slug, label)
+}
+
+// GetAnimeFeed returns the newest anime as an RSS or Atom feed
+func (fc *FeedsController) GetAnimeFeed(c *fiber.Ctx) error {
+	format := c.Params("format")
+	if !validFeedFormat(format) {
+		return fiber.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(feedSize)
+	cursor, err := database.DB.Collection("anime").Find(ctx, bson.M{"slug": bson.M{"$nin": bson.A{"", nil}}}, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var animes []models.Anime
+	if err = cursor.All(ctx, &animes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime",
+			Error:   err.Error(),
+		})
+	}
+
+	f := feed{
+		Title:       "Toofy - New Anime",
+		Link:        fc.siteURL,
+		Self:        fc.baseURL + c.Path(),
+		Description: "Newly added anime on Toofy",
+	}
+	for _, anime := range animes {
+		f.Items = append(f.Items, feedItem{
+			ID:          "anime:" + anime.ID.Hex(),
+			Title:       anime.Title,
+			Link:        fc.animeURL(anime.Slug),
+			Description: anime.Description,
+			Image:       anime.CoverUrl,
+			Published:   anime.CreatedAt,
+			Updated:     anime.UpdatedAt,
+		})
+	}
+
+	return sendFeed(c, format, &f)
+}
+
+// GetEpisodesFeed returns the newest episodes across the catalog as an RSS or Atom feed
+func (fc *FeedsController) GetEpisodesFeed(c *fiber.Ctx) error {
+	format := c.Params("format")
+	if !validFeedFormat(format) {
+		return fiber.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	f := feed{
+		Title:       "Toofy - New Episodes",
+		Link:        fc.siteURL,
+		Self:        fc.baseURL + c.Path(),
+		Description: "Newly published episodes on Toofy",
+	}
+	if err := fc.addEpisodeItems(ctx, &f, bson.M{}, nil); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch episodes",
+			Error:   err.Error(),
+		})
+	}
+
+	return sendFeed(c, format, &f)
+}
+
+// GetAnimeEpisodesFeed returns the newest episodes of a single anime as an RSS or Atom feed
+func (fc *FeedsController) GetAnimeEpisodesFeed(c *fiber.Ctx) error {
+	format := c.Params("format")
+	if !validFeedFormat(format) {
+		return fiber.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var anime models.Anime
+	err := database.DB.Collection("anime").FindOne(ctx, bson.M{"slug": c.Params("slug")}).Decode(&anime)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+
+	f := feed{
+		Title:       fmt.Sprintf("Toofy - %s Episodes", anime.Title),
+		Link:        fc.animeURL(anime.Slug),
+		Self:        fc.baseURL + c.Path(),
+		Description: fmt.Sprintf("New episodes of %s on Toofy", anime.Title),
+	}
+	err = fc.addEpisodeItems(ctx, &f, bson.M{"animeId": anime.ID.Hex()}, map[string]models.Anime{anime.ID.Hex(): anime})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch episodes",
+			Error:   err.Error(),
+		})
+	}
+
+	return sendFeed(c, format, &f)
+}
+
+// addEpisodeItems appends the newest released episodes matching filter to a
+// feed, looking up their anime unless already provided
+func (fc *FeedsController) addEpisodeItems(ctx context.Context, f *feed, filter bson.M, animes map[string]models.Anime) error {
+	opts := options.Find().SetSort(bson.M{"publicAt": -1}).SetLimit(feedSize)
+	cursor, err := database.DB.Collection("episodes").Find(ctx, withEpisodeVisibility(filter, ""), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var episodes []models.Episode
+	if err = cursor.All(ctx, &episodes); err != nil {
+		return err
+	}
+
+	if animes == nil {
+		animes, err = findAnimeByHexIDs(ctx, episodeAnimeIDs(episodes))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, episode := range episodes {
+		anime, ok := animes[episode.AnimeID]
+		if !ok || anime.Slug == "" {
+			continue
+		}
+
+		normalizeEpisode(&episode)
+		title := fmt.Sprintf("%s - Episode %s", anime.Title, episode.Label)
+		if episode.Title != "" {
+			title += ": " + episode.Title
+		}
+
+		f.Items = append(f.Items, feedItem{
+			ID:        "episode:" + episode.ID.Hex(),
+			Title:     title,
+			Link:      fc.episodeURL(anime.Slug, episode.Label),
+			Image:     anime.CoverUrl,
+			Published: episode.PublicAt,
+			Updated:   episode.PublicAt,
+		})
+	}
+	return nil
+}
+
+func episodeAnimeIDs(episodes []models.Episode) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, episode := range episodes {
+		if !seen[episode.AnimeID] {
+			seen[episode.AnimeID] = true
+			ids = append(ids, episode.AnimeID)
+		}
+	}
+	return ids
+}
+
+// findAnimeByHexIDs loads anime by their hex IDs, keyed by hex ID
+func findAnimeByHexIDs(ctx context.Context, ids []string) (map[string]models.Anime, error) {
+	objIDs := []primitive.ObjectID{}
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	animes := map[string]models.Anime{}
+	if len(objIDs) == 0 {
+		return animes, nil
+	}
+
+	cursor, err := database.DB.Collection("anime").Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Anime
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	for _, anime := range results {
+		animes[anime.ID.Hex()] = anime
+	}
+	return animes, nil
+}
+
+func validFeedFormat(format string) bool {
+	return format == "rss" || format == "atom"
+}
+
+// RSS 2.0 document
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description,omitempty"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int    `xml:"length,attr"`
+}
+
+// Atom document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary,omitempty"`
+}
+
+// sendFeed renders a feed in the requested format
+func sendFeed(c *fiber.Ctx, format string, f *feed) error {
+	updated := time.Time{}
+	for _, item := range f.Items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	var doc interface{}
+	contentType := "application/rss+xml; charset=utf-8"
+
+	if format == "atom" {
+		contentType = "application/atom+xml; charset=utf-8"
+		atom := atomFeed{
+			ID:      f.Self,
+			Title:   f.Title,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: f.Link, Rel: "alternate", Type: "text/html"},
+				{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+			},
+		}
+		for _, item := range f.Items {
+			atom.Entries = append(atom.Entries, atomEntry{
+				ID:        "tag:toofy," + item.ID,
+				Title:     item.Title,
+				Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+				Published: item.Published.UTC().Format(time.RFC3339),
+				Updated:   item.Updated.UTC().Format(time.RFC3339),
+				Summary:   item.Description,
+			})
+		}
+		doc = atom
+	} else {
+		rss := rssDocument{
+			Version: "2.0",
+			AtomNS:  "http://www.w3.org/2005/Atom",
+			Channel: rssChannel{
+				Title:         f.Title,
+				Link:          f.Link,
+				AtomLink:      atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+				Description:   f.Description,
+				LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			},
+		}
+		for _, item := range f.Items {
+			rssItem := rssItem{
+				Title:       item.Title,
+				Link:        item.Link,
+				GUID:        rssGUID{Value: "tag:toofy," + item.ID},
+				Description: item.Description,
+				PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			}
+			if item.Image != "" {
+				rssItem.Enclosure = &rssEnclosure{URL: item.Image, Type: imageMIMEType(item.Image)}
+			}
+			rss.Channel.Items = append(rss.Channel.Items, rssItem)
+		}
+		doc = rss
+	}
+
+	return sendXML(c, contentType, doc)
+}
+
+// sendXML writes an XML document with the standard header and a one hour cache
+func sendXML(c *fiber.Ctx, contentType string, doc interface{}) error {
+	output, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to render XML",
+			Error:   err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Cache-Control", "public, max-age=3600")
+	return c.Send(append([]byte(xml.Header), output...))
+}
+
+// imageMIMEType guesses an image content type from its URL extension
+func imageMIMEType(url string) string {
+	switch {
+	case strings.HasSuffix(url, ".png"):
+		return "image/png"
+	case strings.HasSuffix(url, ".webp"):
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
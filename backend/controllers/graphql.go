@@ -0,0 +1,713 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+type graphQLContextKey string
+
+const (
+	claimsContextKey   graphQLContextKey = "claims"
+	loadersContextKey  graphQLContextKey = "loaders"
+	clientIPContextKey graphQLContextKey = "clientIP"
+)
+
+type GraphQLController struct {
+	cfg    *config.Config
+	schema graphql.Schema
+	signer *utils.MediaSigner
+}
+
+func NewGraphQLController(cfg *config.Config) *GraphQLController {
+	gc := &GraphQLController{
+		cfg:    cfg,
+		signer: utils.NewMediaSigner(cfg.MediaSigningKey, cfg.MediaURLTTL, cfg.MediaBindIP),
+	}
+
+	schema, err := gc.buildSchema()
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+	gc.schema = schema
+
+	return gc
+}
+
+// HandleGraphQL executes a GraphQL query sent as JSON (POST) or as query
+// parameters (GET). Authentication is optional for queries; a bearer token,
+// when present, must be valid and is used for `me` and for mutations.
+func (gc *GraphQLController) HandleGraphQL(c *fiber.Ctx) error {
+	var req models.GraphQLRequest
+
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+	} else if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if req.Query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "query is required",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if authHeader := c.Get("Authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid authorization header format",
+			})
+		}
+		claims, err := utils.VerifyToken(parts[1], gc.cfg.JWTSecret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid or expired token",
+			})
+		}
+		ctx = context.WithValue(ctx, claimsContextKey, claims)
+	}
+	role := ""
+	if claims := claimsFromContext(ctx); claims != nil {
+		role = claims.Role
+	}
+	ctx = context.WithValue(ctx, loadersContextKey, newGraphQLLoaders(role))
+	ctx = context.WithValue(ctx, clientIPContextKey, c.IP())
+
+	result := graphql.Do(graphql.Params{
+		Schema:         gc.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+func claimsFromContext(ctx context.Context) *utils.JWTClaims {
+	claims, _ := ctx.Value(claimsContextKey).(*utils.JWTClaims)
+	return claims
+}
+
+func loadersFromContext(ctx context.Context) *graphQLLoaders {
+	return ctx.Value(loadersContextKey).(*graphQLLoaders)
+}
+
+// requireGraphQLPermission returns the caller's claims if they hold the permission
+func requireGraphQLPermission(ctx context.Context, permission string) (*utils.JWTClaims, error) {
+	claims := claimsFromContext(ctx)
+	if claims == nil {
+		return nil, fmt.Errorf("authentication required")
+	}
+	if !models.HasPermission(claims.Role, permission) {
+		return nil, fmt.Errorf("insufficient permissions")
+	}
+	return claims, nil
+}
+
+// hexIDField resolves an ObjectID field as its hex string
+func hexIDField(get func(source interface{}) primitive.ObjectID) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source).Hex(), nil
+		},
+	}
+}
+
+func (gc *GraphQLController) buildSchema() (graphql.Schema, error) {
+	stringList := graphql.NewList(graphql.NewNonNull(graphql.String))
+
+	videoSourceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "VideoSource",
+		Fields: graphql.Fields{
+			"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"server":        &graphql.Field{Type: graphql.String},
+			"type":          &graphql.Field{Type: graphql.String},
+			"url":           &graphql.Field{Type: graphql.String},
+			"quality":       &graphql.Field{Type: graphql.String},
+			"language":      &graphql.Field{Type: graphql.String},
+			"audioLanguage": &graphql.Field{Type: graphql.String},
+			"priority":      &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	subtitleTrackType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SubtitleTrack",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"language":  &graphql.Field{Type: graphql.String},
+			"label":     &graphql.Field{Type: graphql.String},
+			"url":       &graphql.Field{Type: graphql.String},
+			"isDefault": &graphql.Field{Type: graphql.Boolean},
+		},
+	})
+
+	skipMarkerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SkipMarker",
+		Fields: graphql.Fields{
+			"type":   &graphql.Field{Type: graphql.String},
+			"start":  &graphql.Field{Type: graphql.Float},
+			"end":    &graphql.Field{Type: graphql.Float},
+			"source": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	episodeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Episode",
+		Fields: graphql.Fields{
+			"id": hexIDField(func(source interface{}) primitive.ObjectID {
+				return source.(models.Episode).ID
+			}),
+			"animeId":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"type":           &graphql.Field{Type: graphql.String},
+			"season":         &graphql.Field{Type: graphql.Int},
+			"number":         &graphql.Field{Type: graphql.Float},
+			"absoluteNumber": &graphql.Field{Type: graphql.Float},
+			"label":          &graphql.Field{Type: graphql.String},
+			"title":          &graphql.Field{Type: graphql.String},
+			"synopsis":       &graphql.Field{Type: graphql.String},
+			"airDate":        &graphql.Field{Type: graphql.DateTime},
+			"duration":       &graphql.Field{Type: graphql.Int},
+			"thumbnailUrl":   &graphql.Field{Type: graphql.String},
+			"isFiller":       &graphql.Field{Type: graphql.Boolean},
+			"isRecap":        &graphql.Field{Type: graphql.Boolean},
+			"publicAt":       &graphql.Field{Type: graphql.DateTime},
+			"createdAt":      &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":      &graphql.Field{Type: graphql.DateTime},
+			"sources": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(videoSourceType)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := ""
+					if claims := claimsFromContext(p.Context); claims != nil {
+						userID = claims.UserID
+					}
+					ip, _ := p.Context.Value(clientIPContextKey).(string)
+					sources := models.PlayableSources(p.Source.(models.Episode).Sources)
+					return signSources(gc.signer, gc.cfg.BaseURL, sources, userID, ip), nil
+				},
+			},
+			"markers": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(skipMarkerType)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					markers := p.Source.(models.Episode).Markers
+					models.SortMarkers(markers)
+					return markers, nil
+				},
+			},
+			"subtitles": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(subtitleTrackType)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					tracks := p.Source.(models.Episode).Subtitles
+					models.SortSubtitles(tracks)
+					return tracks, nil
+				},
+			},
+		},
+	})
+
+	animeRatingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AnimeRating",
+		Fields: graphql.Fields{
+			"mean":     &graphql.Field{Type: graphql.Float},
+			"count":    &graphql.Field{Type: graphql.Int},
+			"weighted": &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	animeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Anime",
+		Fields: graphql.Fields{
+			"id": hexIDField(func(source interface{}) primitive.ObjectID {
+				return animeValue(source).ID
+			}),
+			"title":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"slug":              &graphql.Field{Type: graphql.String},
+			"alternativeNames":  &graphql.Field{Type: stringList},
+			"description":       &graphql.Field{Type: graphql.String},
+			"coverUrl":          &graphql.Field{Type: graphql.String},
+			"genres":            &graphql.Field{Type: stringList},
+			"status":            &graphql.Field{Type: graphql.String},
+			"type":              &graphql.Field{Type: graphql.String},
+			"episodeCount":      &graphql.Field{Type: graphql.Int},
+			"publishedEpisodes": &graphql.Field{Type: graphql.Int},
+			"latestEpisode":     &graphql.Field{Type: graphql.Float},
+			"lastEpisodeAt":     &graphql.Field{Type: graphql.DateTime},
+			"rating": &graphql.Field{
+				Type: animeRatingType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return animeValue(p.Source).Rating, nil
+				},
+			},
+			"studio":     &graphql.Field{Type: graphql.String},
+			"season":     &graphql.Field{Type: graphql.String},
+			"seasonYear": &graphql.Field{Type: graphql.Int},
+			"createdAt":  &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":  &graphql.Field{Type: graphql.DateTime},
+			"episodes": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(episodeType)),
+				Args: episodePageArgs(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					anime := animeValue(p.Source)
+					return loadEpisodePage(p, anime.ID.Hex())
+				},
+			},
+		},
+	})
+
+	// Episodes link back to their anime through the batched loader
+	episodeType.AddFieldConfig("anime", &graphql.Field{
+		Type: animeType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			episode := p.Source.(models.Episode)
+			return loadersFromContext(p.Context).anime.load(p.Context, episode.AnimeID), nil
+		},
+	})
+
+	sliderItemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SliderItem",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"animeId":    &graphql.Field{Type: graphql.ID},
+			"title":      &graphql.Field{Type: graphql.String},
+			"coverUrl":   &graphql.Field{Type: graphql.String},
+			"status":     &graphql.Field{Type: graphql.String},
+			"type":       &graphql.Field{Type: graphql.String},
+			"season":     &graphql.Field{Type: graphql.String},
+			"seasonYear": &graphql.Field{Type: graphql.Int},
+			"order":      &graphql.Field{Type: graphql.Int},
+			"anime": &graphql.Field{
+				Type: animeType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					item := p.Source.(SliderItem)
+					return loadersFromContext(p.Context).anime.load(p.Context, item.AnimeID), nil
+				},
+			},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": hexIDField(func(source interface{}) primitive.ObjectID {
+				return source.(*models.User).ID
+			}),
+			"displayName": &graphql.Field{Type: graphql.String},
+			"email":       &graphql.Field{Type: graphql.String},
+			"role":        &graphql.Field{Type: graphql.String},
+			"permissions": &graphql.Field{Type: stringList},
+			"isActive":    &graphql.Field{Type: graphql.Boolean},
+			"createdAt":   &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	animePageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AnimePage",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(animeType))},
+			"total":      &graphql.Field{Type: graphql.Int},
+			"page":       &graphql.Field{Type: graphql.Int},
+			"limit":      &graphql.Field{Type: graphql.Int},
+			"totalPages": &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	animeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AnimeInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":            &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"slug":             &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"alternativeNames": &graphql.InputObjectFieldConfig{Type: stringList},
+			"description":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"coverUrl":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"genres":           &graphql.InputObjectFieldConfig{Type: stringList},
+			"status":           &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"type":             &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"episodeCount":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"studio":           &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"season":           &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"seasonYear":       &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"anime": &graphql.Field{
+				Type: animeType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.ID},
+					"slug": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: gc.resolveAnime,
+			},
+			"animeList": &graphql.Field{
+				Type: animePageType,
+				Args: graphql.FieldConfigArgument{
+					"page":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 30},
+				},
+				Resolve: gc.resolveAnimeList,
+			},
+			"episodes": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(episodeType)),
+				Args: func() graphql.FieldConfigArgument {
+					args := episodePageArgs()
+					args["animeId"] = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+					return args
+				}(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadEpisodePage(p, p.Args["animeId"].(string))
+				},
+			},
+			"slider": &graphql.Field{
+				Type:    graphql.NewList(graphql.NewNonNull(sliderItemType)),
+				Resolve: gc.resolveSlider,
+			},
+			"me": &graphql.Field{
+				Type:    userType,
+				Resolve: gc.resolveMe,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createAnime": &graphql.Field{
+				Type: animeType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(animeInputType)},
+					"force": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: gc.resolveCreateAnime,
+			},
+			"updateAnime": &graphql.Field{
+				Type: animeType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(animeInputType)},
+				},
+				Resolve: gc.resolveUpdateAnime,
+			},
+			"deleteAnime": &graphql.Field{
+				Type: graphql.Boolean,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: gc.resolveDeleteAnime,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// episodePageArgs are the arguments of the episode list fields. Lists are
+// always paginated, at most 100 episodes per page.
+func episodePageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"season": &graphql.ArgumentConfig{Type: graphql.Int},
+		"page":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 50},
+	}
+}
+
+// loadEpisodePage queues a page of an anime's episodes on the batched loader
+func loadEpisodePage(p graphql.ResolveParams, animeID string) (interface{}, error) {
+	season, _ := p.Args["season"].(int)
+	if _, ok := p.Args["season"]; ok && season < 1 {
+		return nil, fmt.Errorf("season must be a positive number")
+	}
+	page, _ := p.Args["page"].(int)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	query := episodePage{animeID: animeID, season: season, page: page, limit: limit}
+	return loadersFromContext(p.Context).episodes.load(p.Context, query.key()), nil
+}
+
+func (gc *GraphQLController) resolveAnime(p graphql.ResolveParams) (interface{}, error) {
+	filter := bson.M{}
+	if id, ok := p.Args["id"].(string); ok {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anime ID")
+		}
+		filter["_id"] = objID
+	} else if slug, ok := p.Args["slug"].(string); ok {
+		filter["slug"] = slug
+	} else {
+		return nil, fmt.Errorf("id or slug is required")
+	}
+
+	var anime models.Anime
+	if err := database.DB.Collection("anime").FindOne(p.Context, filter).Decode(&anime); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &anime, nil
+}
+
+func (gc *GraphQLController) resolveAnimeList(p graphql.ResolveParams) (interface{}, error) {
+	page, _ := p.Args["page"].(int)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := p.Args["limit"].(int)
+	if limit < 1 {
+		limit = 30
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	animeCollection := database.DB.Collection("anime")
+
+	total, err := animeCollection.CountDocuments(p.Context, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit)).SetSort(bson.M{"createdAt": -1})
+	cursor, err := animeCollection.Find(p.Context, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.Context)
+
+	var animes []models.Anime
+	if err = cursor.All(p.Context, &animes); err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.Anime, len(animes))
+	for i := range animes {
+		items[i] = &animes[i]
+	}
+
+	return map[string]interface{}{
+		"items":      items,
+		"total":      int(total),
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (int(total) + limit - 1) / limit,
+	}, nil
+}
+
+func (gc *GraphQLController) resolveSlider(p graphql.ResolveParams) (interface{}, error) {
+	opts := options.Find().SetSort(bson.M{"order": 1})
+	cursor, err := database.DB.Collection("sliders").Find(p.Context, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(p.Context)
+
+	var items []SliderItem
+	if err = cursor.All(p.Context, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []SliderItem{}
+	}
+	return items, nil
+}
+
+func (gc *GraphQLController) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	claims := claimsFromContext(p.Context)
+	if claims == nil {
+		return nil, fmt.Errorf("authentication required")
+	}
+
+	objID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	var user models.User
+	if err := database.DB.Collection("users").FindOne(p.Context, bson.M{"_id": objID}).Decode(&user); err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return &user, nil
+}
+
+// animeRequestFromInput converts an AnimeInput argument to the REST request
+// type so both APIs share validation
+func animeRequestFromInput(input map[string]interface{}) models.AnimeRequest {
+	str := func(key string) string {
+		value, _ := input[key].(string)
+		return value
+	}
+	num := func(key string) int {
+		value, _ := input[key].(int)
+		return value
+	}
+	list := func(key string) []string {
+		values, _ := input[key].([]interface{})
+		result := []string{}
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	return models.AnimeRequest{
+		Title:            str("title"),
+		Slug:             str("slug"),
+		AlternativeNames: list("alternativeNames"),
+		Description:      str("description"),
+		CoverUrl:         str("coverUrl"),
+		Genres:           list("genres"),
+		Status:           str("status"),
+		Type:             str("type"),
+		EpisodeCount:     num("episodeCount"),
+		Studio:           str("studio"),
+		Season:           str("season"),
+		SeasonYear:       num("seasonYear"),
+	}
+}
+
+// checkReviewMode rejects direct writes from editors while review mode is on;
+// those changes must be submitted through the REST API for approval
+func (gc *GraphQLController) checkReviewMode(claims *utils.JWTClaims) error {
+	if gc.cfg.EditorReviewMode && claims.Role == "editor" {
+		return fmt.Errorf("editor changes require review; submit them through the REST API")
+	}
+	return nil
+}
+
+func (gc *GraphQLController) resolveCreateAnime(p graphql.ResolveParams) (interface{}, error) {
+	claims, err := requireGraphQLPermission(p.Context, models.PermCreateAnime)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.checkReviewMode(claims); err != nil {
+		return nil, err
+	}
+
+	req := animeRequestFromInput(p.Args["input"].(map[string]interface{}))
+	if msg := req.Validate(); msg != "" {
+		return nil, fmt.Errorf("%s", msg)
+	}
+
+	anime := req.ToAnime()
+
+	if force, _ := p.Args["force"].(bool); !force {
+		candidates, err := findDuplicateCandidates(p.Context, &anime)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			titles := make([]string, len(candidates))
+			for i, candidate := range candidates {
+				titles[i] = fmt.Sprintf("%s (%s)", candidate.Anime.Title, candidate.Anime.ID.Hex())
+			}
+			return nil, fmt.Errorf("possible duplicate anime found: %s; pass force: true to create it anyway", strings.Join(titles, ", "))
+		}
+	}
+
+	if err := insertAnime(p.Context, &anime); err != nil {
+		return nil, err
+	}
+	return &anime, nil
+}
+
+func (gc *GraphQLController) resolveUpdateAnime(p graphql.ResolveParams) (interface{}, error) {
+	claims, err := requireGraphQLPermission(p.Context, models.PermEditAnime)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.checkReviewMode(claims); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid anime ID")
+	}
+
+	req := animeRequestFromInput(p.Args["input"].(map[string]interface{}))
+	if msg := req.Validate(); msg != "" {
+		return nil, fmt.Errorf("%s", msg)
+	}
+
+	anime := req.ToAnime()
+	matched, err := updateAnime(p.Context, objID, &anime)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return nil, fmt.Errorf("anime not found")
+	}
+
+	var updated models.Anime
+	if err := database.DB.Collection("anime").FindOne(p.Context, bson.M{"_id": objID}).Decode(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (gc *GraphQLController) resolveDeleteAnime(p graphql.ResolveParams) (interface{}, error) {
+	claims, err := requireGraphQLPermission(p.Context, models.PermDeleteAnime)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.checkReviewMode(claims); err != nil {
+		return nil, err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid anime ID")
+	}
+
+	deleted, err := deleteAnime(p.Context, objID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, fmt.Errorf("anime not found")
+	}
+	return true, nil
+}
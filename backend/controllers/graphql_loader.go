@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// batchLoader collects keys requested while a GraphQL level is being
+// resolved and fetches them with a single query once the first result is
+// needed, dataloader style. Loaders live for a single request.
+type batchLoader struct {
+	mu      sync.Mutex
+	fetch   func(ctx context.Context, keys []string) (map[string]interface{}, error)
+	pending []string
+	queued  map[string]bool
+	results map[string]interface{}
+	errors  map[string]error
+}
+
+func newBatchLoader(fetch func(ctx context.Context, keys []string) (map[string]interface{}, error)) *batchLoader {
+	return &batchLoader{
+		fetch:   fetch,
+		queued:  map[string]bool{},
+		results: map[string]interface{}{},
+		errors:  map[string]error{},
+	}
+}
+
+// load queues a key and returns a thunk resolving to its value. graphql-go
+// calls the thunks after resolving every field at the current level, so all
+// keys queued by sibling fields are fetched together.
+func (l *batchLoader) load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+
+			results, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errors[k] = err
+				} else if value, ok := results[k]; ok {
+					l.results[k] = value
+				}
+			}
+		}
+
+		if err := l.errors[key]; err != nil {
+			return nil, err
+		}
+		return l.results[key], nil
+	}
+}
+
+// graphQLLoaders are the per-request loaders used by GraphQL resolvers
+type graphQLLoaders struct {
+	anime    *batchLoader // anime hex ID -> *models.Anime
+	episodes *batchLoader // episodePage key -> []models.Episode
+}
+
+// newGraphQLLoaders creates the loaders of a request; episodes are limited to
+// those visible to the caller's role
+func newGraphQLLoaders(role string) *graphQLLoaders {
+	return &graphQLLoaders{
+		anime: newBatchLoader(func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+			animes, err := findAnimeByHexIDs(ctx, keys)
+			if err != nil {
+				return nil, err
+			}
+			results := map[string]interface{}{}
+			for id := range animes {
+				anime := animes[id]
+				results[id] = &anime
+			}
+			return results, nil
+		}),
+		episodes: newBatchLoader(func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+			return fetchEpisodePages(ctx, keys, role)
+		}),
+	}
+}
+
+// animeValue unwraps the anime a loader or resolver returned
+func animeValue(source interface{}) *models.Anime {
+	switch anime := source.(type) {
+	case *models.Anime:
+		return anime
+	case models.Anime:
+		return &anime
+	}
+	return nil
+}
+
+// episodePage selects a page of one anime's episodes, optionally limited to a
+// season. It is encoded as the key of the episodes loader.
+type episodePage struct {
+	animeID string
+	season  int // 0 for every season
+	page    int
+	limit   int
+}
+
+func (q episodePage) key() string {
+	return fmt.Sprintf("%s/%d/%d/%d", q.animeID, q.season, q.page, q.limit)
+}
+
+func parseEpisodePage(key string) (episodePage, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		return episodePage{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts[1:] {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return episodePage{}, false
+		}
+		numbers[i] = n
+	}
+	return episodePage{parts[0], numbers[0], numbers[1], numbers[2]}, true
+}
+
+// fetchEpisodePages loads the episode pages of a batch. Keys that share a
+// season and page are resolved together: one aggregation picks the episode
+// IDs of each anime's page, and one query loads the episodes of every page.
+func fetchEpisodePages(ctx context.Context, keys []string, role string) (map[string]interface{}, error) {
+	type pageQuery struct{ season, page, limit int }
+	groups := map[pageQuery][]string{}
+	for _, key := range keys {
+		q, ok := parseEpisodePage(key)
+		if !ok {
+			continue
+		}
+		group := pageQuery{q.season, q.page, q.limit}
+		groups[group] = append(groups[group], q.animeID)
+	}
+
+	collection := database.DB.Collection("episodes")
+	pageIDs := map[string][]primitive.ObjectID{}
+	allIDs := []primitive.ObjectID{}
+	for group, animeIDs := range groups {
+		filter := bson.M{"animeId": bson.M{"$in": animeIDs}}
+		if group.season > 0 {
+			filter["season"] = group.season
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: withEpisodeVisibility(filter, role)}},
+			{{Key: "$sort", Value: models.EpisodeSort}},
+			{{Key: "$group", Value: bson.M{"_id": "$animeId", "ids": bson.M{"$push": "$_id"}}}},
+			{{Key: "$project", Value: bson.M{"ids": bson.M{"$slice": bson.A{"$ids", (group.page - 1) * group.limit, group.limit}}}}},
+		}
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		var pages []struct {
+			AnimeID string               `bson:"_id"`
+			IDs     []primitive.ObjectID `bson:"ids"`
+		}
+		err = cursor.All(ctx, &pages)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, page := range pages {
+			key := episodePage{page.AnimeID, group.season, group.page, group.limit}.key()
+			pageIDs[key] = page.IDs
+			allIDs = append(allIDs, page.IDs...)
+		}
+	}
+
+	episodes := map[primitive.ObjectID]models.Episode{}
+	if len(allIDs) > 0 {
+		cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": allIDs}})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		var found []models.Episode
+		if err = cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		for _, episode := range found {
+			normalizeEpisode(&episode)
+			episodes[episode.ID] = episode
+		}
+	}
+
+	results := map[string]interface{}{}
+	for _, key := range keys {
+		list := []models.Episode{}
+		for _, id := range pageIDs[key] {
+			if episode, ok := episodes[id]; ok {
+				list = append(list, episode)
+			}
+		}
+		results[key] = list
+	}
+	return results, nil
+}
@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+type LibraryController struct{}
+
+func NewLibraryController() *LibraryController {
+	return &LibraryController{}
+}
+
+// GetLibrary lists the current user's library, most recently updated first,
+// optionally limited to one watch status
+func (lc *LibraryController) GetLibrary(c *fiber.Ctx) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "30"))
+	if err != nil || limitNum < 1 || limitNum > 100 {
+		limitNum = 30
+	}
+
+	filter := bson.M{"userId": c.Locals("userID").(string)}
+	if status := c.Query("status"); status != "" {
+		if !models.IsLibraryStatus(status) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Invalid status. Must be: watching, completed, on_hold, dropped, or plan_to_watch",
+			})
+		}
+		filter["status"] = status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	libraryCollection := database.DB.Collection("library")
+
+	total, err := libraryCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count library entries",
+			Error:   err.Error(),
+		})
+	}
+
+	opts := options.Find().
+		SetSkip(int64((pageNum - 1) * limitNum)).
+		SetLimit(int64(limitNum)).
+		SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := libraryCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch library",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.LibraryEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse library",
+			Error:   err.Error(),
+		})
+	}
+
+	animeIDs := []string{}
+	for _, entry := range entries {
+		animeIDs = append(animeIDs, entry.AnimeID)
+	}
+	animes, err := findAnimeByHexIDs(ctx, animeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	for i := range entries {
+		if anime, ok := animes[entries[i].AnimeID]; ok {
+			ref := anime.Ref()
+			entries[i].Anime = &ref
+		}
+	}
+
+	if entries == nil {
+		entries = []models.LibraryEntry{}
+	}
+
+	return respondPage(c, "Library retrieved successfully", "entries", entries, pageMeta(total, pageNum, limitNum))
+}
+
+// GetLibraryCounts returns how many anime the current user has per watch
+// status
+func (lc *LibraryController) GetLibraryCounts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": c.Locals("userID").(string)}}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := database.DB.Collection("library").Aggregate(ctx, pipeline)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count library entries",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse library counts",
+			Error:   err.Error(),
+		})
+	}
+
+	counts := models.LibraryCounts{Counts: map[string]int{}}
+	for _, status := range models.LibraryStatuses {
+		counts.Counts[status] = 0
+	}
+	for _, group := range groups {
+		counts.Counts[group.Status] = group.Count
+		counts.Total += group.Count
+	}
+
+	return respond(c, fiber.StatusOK, "Library counts retrieved successfully", "", counts)
+}
+
+// GetLibraryEntry returns the current user's entry for an anime
+func (lc *LibraryController) GetLibraryEntry(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var entry models.LibraryEntry
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": c.Params("animeId")}
+	err := database.DB.Collection("library").FindOne(ctx, filter).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime is not in your library",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch library entry",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Library entry retrieved successfully", "entry", entry)
+}
+
+// PutLibraryEntry adds an anime to the current user's library or replaces
+// its entry. Completing an anime with a known episode count marks every
+// episode watched.
+func (lc *LibraryController) PutLibraryEntry(c *fiber.Ctx) error {
+	animeID := c.Params("animeId")
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	var req models.LibraryEntryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var anime models.Anime
+	err = database.DB.Collection("anime").FindOne(ctx, bson.M{"_id": objID}).Decode(&anime)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Notes = strings.TrimSpace(req.Notes)
+	if msg := req.Validate(anime.EpisodeCount); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	if req.Status == models.LibraryCompleted && anime.EpisodeCount > 0 {
+		req.Progress = anime.EpisodeCount
+	}
+
+	// The library score is the user's rating of the anime
+	userID := c.Locals("userID").(string)
+	var rating models.Rating
+	err = database.DB.Collection("ratings").FindOne(ctx, bson.M{"userId": userID, "animeId": animeID}).Decode(&rating)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch rating",
+			Error:   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"status":    req.Status,
+		"progress":  req.Progress,
+		"notes":     req.Notes,
+		"updatedAt": now,
+	}
+	setOnInsert := bson.M{"createdAt": now}
+	if req.Score != nil {
+		set["score"] = *req.Score
+	} else {
+		setOnInsert["score"] = rating.Score
+	}
+	unset := bson.M{}
+	if req.StartedAt != nil {
+		set["startedAt"] = req.StartedAt
+	} else {
+		unset["startedAt"] = ""
+	}
+	if req.FinishedAt != nil {
+		set["finishedAt"] = req.FinishedAt
+	} else {
+		unset["finishedAt"] = ""
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": setOnInsert,
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var entry models.LibraryEntry
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = database.DB.Collection("library").FindOneAndUpdate(ctx, bson.M{"userId": userID, "animeId": animeID}, update, opts).Decode(&entry)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to save library entry",
+			Error:   err.Error(),
+		})
+	}
+
+	if req.Score != nil && *req.Score != rating.Score {
+		if *req.Score > 0 {
+			_, err = setRating(ctx, userID, animeID, *req.Score)
+		} else {
+			_, err = removeRating(ctx, userID, animeID)
+		}
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to save rating",
+			Error:   err.Error(),
+		})
+	}
+
+	ref := anime.Ref()
+	entry.Anime = &ref
+
+	return respond(c, fiber.StatusOK, "Library entry saved successfully", "entry", entry)
+}
+
+// DeleteLibraryEntry removes an anime from the current user's library
+func (lc *LibraryController) DeleteLibraryEntry(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": c.Params("animeId")}
+	result, err := database.DB.Collection("library").DeleteOne(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete library entry",
+			Error:   err.Error(),
+		})
+	}
+
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime is not in your library",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Library entry deleted successfully", "", nil)
+}
+
+// mergeUserEntries repoints the entries of a per-user collection (one entry
+// per user and anime) from merged anime to the survivor. A user who already
+// has an entry for the survivor keeps it; of several merged entries the most
+// recently updated one wins.
+func mergeUserEntries(ctx context.Context, collection, survivorID string, loserIDs []string) error {
+	entriesCollection := database.DB.Collection(collection)
+
+	opts := options.Find().SetSort(bson.M{"updatedAt": -1}).SetProjection(bson.M{"_id": 1})
+	cursor, err := entriesCollection.Find(ctx, bson.M{"animeId": bson.M{"$in": loserIDs}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		_, err := entriesCollection.UpdateOne(ctx, bson.M{"_id": entry.ID}, bson.M{"$set": bson.M{"animeId": survivorID}})
+		if mongo.IsDuplicateKeyError(err) {
+			_, err = entriesCollection.DeleteOne(ctx, bson.M{"_id": entry.ID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/config"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// mediaPath is the route prefix episode media files are served under; source
+// URLs starting with BASE_URL + mediaPath are hosted here and get signed
+const mediaPath = "/media/"
+
+// maxPlaylistSize limits the HLS playlists read into memory for rewriting
+const maxPlaylistSize = 4 << 20
+
+type MediaController struct {
+	upload  *UploadController
+	baseURL string
+	// segmentSigner signs the URIs inside HLS playlists, which live shorter
+	// than the playlist URLs handed out with episodes
+	segmentSigner *utils.MediaSigner
+}
+
+func NewMediaController(cfg *config.Config, upload *UploadController) *MediaController {
+	return &MediaController{
+		upload:        upload,
+		baseURL:       strings.TrimRight(cfg.BaseURL, "/"),
+		segmentSigner: utils.NewMediaSigner(cfg.MediaSigningKey, cfg.MediaSegmentTTL, cfg.MediaBindIP),
+	}
+}
+
+// GetMedia streams an episode video, HLS segment or download from storage.
+// Requests must pass middleware.SignedMedia. HLS playlists are rewritten so
+// every URI in them is signed for the same user.
+func (mc *MediaController) GetMedia(c *fiber.Ctx) error {
+	key := c.Params("*")
+	if key == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Key required",
+		})
+	}
+
+	grant, _ := c.Locals("mediaGrant").(*utils.MediaGrant)
+	if grant == nil {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Success: false,
+			Message: utils.ErrMediaURLInvalid.Error(),
+		})
+	}
+
+	if strings.EqualFold(path.Ext(key), ".m3u8") {
+		return mc.servePlaylist(c, key, grant)
+	}
+
+	if grant.Download {
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+path.Base(key)+`"`)
+	}
+	// Signed URLs are per user: the browser may keep the object until the
+	// URL expires, shared caches may not
+	maxAge := int(time.Until(grant.ExpiresAt).Seconds())
+	return mc.upload.streamObject(c, key, fmt.Sprintf("private, max-age=%d, immutable", maxAge))
+}
+
+// servePlaylist serves an HLS master or media playlist with its variant,
+// segment, key and map URIs replaced by signed media URLs
+func (mc *MediaController) servePlaylist(c *fiber.Ctx, key string, grant *utils.MediaGrant) error {
+	obj, err := mc.upload.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(mc.upload.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "File not found",
+		})
+	}
+	defer obj.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(obj.Body, maxPlaylistSize+1))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to read playlist",
+			Error:   err.Error(),
+		})
+	}
+	if len(data) > maxPlaylistSize {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Playlist is too large",
+		})
+	}
+
+	dir := path.Dir(key)
+	playlist, err := utils.RewriteHLSPlaylist(string(data), func(uri string) (string, error) {
+		return mc.signPlaylistURI(uri, dir, grant.UserID, c.IP())
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to rewrite playlist",
+			Error:   err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/vnd.apple.mpegurl")
+	// The signed URIs inside are only valid for this user and a short time
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.SendString(playlist)
+}
+
+// signPlaylistURI resolves a playlist URI against the playlist's directory
+// and signs it. Absolute URIs on other hosts are left as they are.
+func (mc *MediaController) signPlaylistURI(uri string, dir string, userID string, ip string) (string, error) {
+	ref, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	var key string
+	switch {
+	case ref.IsAbs() && isHostedMedia(uri, mc.baseURL):
+		key = strings.TrimPrefix(path.Clean(ref.Path), mediaPath)
+	case ref.IsAbs() || ref.Host != "":
+		return uri, nil
+	case strings.HasPrefix(ref.Path, "/"):
+		cleaned := path.Clean(ref.Path)
+		if !strings.HasPrefix(cleaned, mediaPath) {
+			return "", fmt.Errorf("playlist URI %q is outside the media storage", uri)
+		}
+		key = strings.TrimPrefix(cleaned, mediaPath)
+	default:
+		key = path.Join(dir, ref.Path)
+	}
+	if key == "" || key == "." || strings.HasPrefix(key, "..") {
+		return "", fmt.Errorf("playlist URI %q is outside the media storage", uri)
+	}
+
+	mediaURL := mc.baseURL + (&url.URL{Path: mediaPath + key}).EscapedPath()
+	signed, _, err := mc.segmentSigner.Sign(mediaURL, userID, ip, false)
+	return signed, err
+}
+
+// isHostedMedia reports whether a source URL is served by GetMedia
+func isHostedMedia(url string, baseURL string) bool {
+	return strings.HasPrefix(url, strings.TrimRight(baseURL, "/")+mediaPath)
+}
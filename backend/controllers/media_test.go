@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"toofy-backend/utils"
+)
+
+func TestSignPlaylistURI(t *testing.T) {
+	mc := &MediaController{
+		baseURL:       "https://api.example.com",
+		segmentSigner: utils.NewMediaSigner("test-key", time.Minute, false),
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantPath string // path of the signed URL, empty when kept as is
+		wantKept bool
+		wantErr  bool
+	}{
+		{name: "relative", uri: "seg0.ts", wantPath: "/media/ep1/hls/seg0.ts"},
+		{name: "relative subdirectory", uri: "720p/seg0.ts", wantPath: "/media/ep1/hls/720p/seg0.ts"},
+		{name: "relative parent inside storage", uri: "../poster.jpg", wantPath: "/media/ep1/poster.jpg"},
+		{name: "root relative", uri: "/media/ep2/seg0.ts", wantPath: "/media/ep2/seg0.ts"},
+		{name: "absolute hosted", uri: "https://api.example.com/media/ep1/hls/seg0.ts", wantPath: "/media/ep1/hls/seg0.ts"},
+		{name: "absolute other host", uri: "https://cdn.example.com/seg0.ts", wantKept: true},
+		{name: "protocol relative", uri: "//cdn.example.com/seg0.ts", wantKept: true},
+		{name: "relative outside storage", uri: "../../../secret.ts", wantErr: true},
+		{name: "root relative outside storage", uri: "/etc/passwd", wantErr: true},
+		{name: "root relative escaping storage", uri: "/media/../etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mc.signPlaylistURI(tt.uri, "ep1/hls", "user1", "203.0.113.7")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("signPlaylistURI(%q) = %q, want an error", tt.uri, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("signPlaylistURI(%q) error = %v", tt.uri, err)
+			}
+			if tt.wantKept {
+				if got != tt.uri {
+					t.Errorf("signPlaylistURI(%q) = %q, want it unchanged", tt.uri, got)
+				}
+				return
+			}
+
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(got, mc.baseURL) || u.Path != tt.wantPath {
+				t.Errorf("signPlaylistURI(%q) = %q, want %s%s", tt.uri, got, mc.baseURL, tt.wantPath)
+			}
+			if _, err := mc.segmentSigner.Verify(u.EscapedPath(), u.Query(), "203.0.113.7"); err != nil {
+				t.Errorf("signed URL does not verify: %v", err)
+			}
+		})
+	}
+}
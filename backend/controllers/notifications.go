@@ -0,0 +1,463 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/handlers"
+	"toofy-backend/models"
+)
+
+// notificationBatchSize is how many notifications are inserted per write
+// when notifying many users
+const notificationBatchSize = 1000
+
+type NotificationsController struct{}
+
+func NewNotificationsController() *NotificationsController {
+	return &NotificationsController{}
+}
+
+// notifyUsers stores a copy of a notification in the inbox of each user and
+// pushes it to their open websocket connections
+func notifyUsers(ctx context.Context, userIDs []string, notification models.Notification) error {
+	now := time.Now()
+	for start := 0; start < len(userIDs); start += notificationBatchSize {
+		end := start + notificationBatchSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+
+		batch := map[string]interface{}{}
+		docs := []interface{}{}
+		for _, userID := range userIDs[start:end] {
+			n := notification
+			n.ID = primitive.NewObjectID()
+			n.UserID = userID
+			n.Read = false
+			n.CreatedAt = now
+			batch[userID] = n
+			docs = append(docs, n)
+		}
+
+		if _, err := database.DB.Collection("notifications").InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+			return err
+		}
+		handlers.NotifyEachUser(notification.Type, batch)
+	}
+	return nil
+}
+
+// notifyFollowers notifies every follower of an anime in the background, so
+// the change that caused it does not wait on a large fan-out
+func notifyFollowers(animeID string, notification models.Notification) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		if err := notifyFollowersNow(ctx, animeID, notification); err != nil {
+			fmt.Printf("Warning: Failed to notify followers of anime %s: %v\n", animeID, err)
+		}
+	}()
+}
+
+func notifyFollowersNow(ctx context.Context, animeID string, notification models.Notification) error {
+	followers, err := database.DB.Collection("follows").Distinct(ctx, "userId", bson.M{"animeId": animeID})
+	if err != nil || len(followers) == 0 {
+		return err
+	}
+
+	userIDs := []string{}
+	for _, id := range followers {
+		if userID, ok := id.(string); ok {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	if notification.AnimeTitle == "" {
+		animes, err := findAnimeByHexIDs(ctx, []string{animeID})
+		if err != nil {
+			return err
+		}
+		notification.AnimeTitle = animes[animeID].Title
+	}
+	notification.AnimeID = animeID
+
+	return notifyUsers(ctx, userIDs, notification)
+}
+
+// notifyEpisodeReleased tells the followers of an anime about a released
+// episode
+func notifyEpisodeReleased(episode *models.Episode) {
+	normalizeEpisode(episode)
+	notifyFollowers(episode.AnimeID, models.Notification{
+		Type:         models.NotificationNewEpisode,
+		EpisodeID:    episode.ID.Hex(),
+		EpisodeLabel: episode.Label,
+		Message:      fmt.Sprintf("Episode %s is out", episode.Label),
+	})
+}
+
+// notifyStatusChanged tells the followers of an anime about its new status
+func notifyStatusChanged(animeID string, title string, status string) {
+	notifyFollowers(animeID, models.Notification{
+		Type:       models.NotificationStatusChange,
+		AnimeTitle: title,
+		Status:     status,
+		Message:    fmt.Sprintf("%s is now %s", title, status),
+	})
+}
+
+// notifySourcesAdded tells the followers of an anime about sources added to
+// a released episode. Several sources added at once make one notification.
+func notifySourcesAdded(episode *models.Episode, previous []models.VideoSource) {
+	if !episodeVisibleTo("", episode) {
+		return
+	}
+
+	added := []models.VideoSource{}
+	for _, source := range episode.Sources {
+		if !source.Disabled && !hasSource(previous, source.ID) {
+			added = append(added, source)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	normalizeEpisode(episode)
+	notifyFollowers(episode.AnimeID, models.Notification{
+		Type:         models.NotificationNewSource,
+		EpisodeID:    episode.ID.Hex(),
+		EpisodeLabel: episode.Label,
+		SourceID:     added[0].ID,
+		Message:      fmt.Sprintf("Episode %s is now available on %s", episode.Label, added[0].Server),
+	})
+}
+
+// FollowAnime subscribes the current user to the notifications of an anime
+func (nc *NotificationsController) FollowAnime(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := database.DB.Collection("anime").CountDocuments(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	if count == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+
+	var follow models.Follow
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": objID.Hex()}
+	update := bson.M{"$setOnInsert": bson.M{"createdAt": time.Now()}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = database.DB.Collection("follows").FindOneAndUpdate(ctx, filter, update, opts).Decode(&follow)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to follow anime",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Anime followed successfully", "follow", follow)
+}
+
+// UnfollowAnime unsubscribes the current user from an anime
+func (nc *NotificationsController) UnfollowAnime(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": c.Params("id")}
+	result, err := database.DB.Collection("follows").DeleteOne(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to unfollow anime",
+			Error:   err.Error(),
+		})
+	}
+
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You do not follow this anime",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Anime unfollowed successfully", "", nil)
+}
+
+// GetFollowStatus reports whether the current user follows an anime
+func (nc *NotificationsController) GetFollowStatus(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": c.Params("id")}
+	count, err := database.DB.Collection("follows").CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch follow",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Follow status retrieved successfully", "following", count > 0)
+}
+
+// GetFollows lists the anime the current user follows, most recent first
+func (nc *NotificationsController) GetFollows(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := database.DB.Collection("follows").Find(ctx, bson.M{"userId": c.Locals("userID").(string)}, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch follows",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var follows []models.Follow
+	if err = cursor.All(ctx, &follows); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse follows",
+			Error:   err.Error(),
+		})
+	}
+
+	animeIDs := []string{}
+	for _, follow := range follows {
+		animeIDs = append(animeIDs, follow.AnimeID)
+	}
+	animes, err := findAnimeByHexIDs(ctx, animeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	for i := range follows {
+		if anime, ok := animes[follows[i].AnimeID]; ok {
+			ref := anime.Ref()
+			follows[i].Anime = &ref
+		}
+	}
+
+	if follows == nil {
+		follows = []models.Follow{}
+	}
+
+	return respond(c, fiber.StatusOK, "Follows retrieved successfully", "follows", follows)
+}
+
+// notificationInbox is the v2 data of an inbox page. The unread count covers
+// the whole inbox, so it goes next to the notifications rather than in meta.
+type notificationInbox struct {
+	Notifications []models.Notification `json:"notifications"`
+	Unread        int64                 `json:"unread"`
+}
+
+// GetNotifications lists the current user's notifications, newest first,
+// with the number of unread ones
+func (nc *NotificationsController) GetNotifications(c *fiber.Ctx) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "30"))
+	if err != nil || limitNum < 1 || limitNum > 100 {
+		limitNum = 30
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notificationsCollection := database.DB.Collection("notifications")
+	userID := c.Locals("userID").(string)
+	filter := bson.M{"userId": userID}
+	if c.Query("unread") == "true" {
+		filter["read"] = false
+	}
+
+	total, err := notificationsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count notifications",
+			Error:   err.Error(),
+		})
+	}
+	unread, err := notificationsCollection.CountDocuments(ctx, bson.M{"userId": userID, "read": false})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count notifications",
+			Error:   err.Error(),
+		})
+	}
+
+	opts := options.Find().
+		SetSkip(int64((pageNum - 1) * limitNum)).
+		SetLimit(int64(limitNum)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := notificationsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch notifications",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []models.Notification
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse notifications",
+			Error:   err.Error(),
+		})
+	}
+
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+
+	meta := pageMeta(total, pageNum, limitNum)
+	if isV2(c) {
+		return respondPage(c, "Notifications retrieved successfully", "", notificationInbox{Notifications: notifications, Unread: unread}, meta)
+	}
+	return respond(c, fiber.StatusOK, "Notifications retrieved successfully", "", fiber.Map{
+		"notifications": notifications,
+		"unread":        unread,
+		"total":         total,
+		"page":          pageNum,
+		"limit":         limitNum,
+		"total_pages":   meta.TotalPages,
+	})
+}
+
+// MarkNotificationRead marks one of the current user's notifications read
+func (nc *NotificationsController) MarkNotificationRead(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid notification ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// readAt keeps the time the notification was first read
+	filter := bson.M{"_id": objID, "userId": c.Locals("userID").(string)}
+	update := bson.A{bson.M{"$set": bson.M{
+		"read":   true,
+		"readAt": bson.M{"$ifNull": bson.A{"$readAt", time.Now()}},
+	}}}
+
+	var notification models.Notification
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("notifications").FindOneAndUpdate(ctx, filter, update, opts).Decode(&notification)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Notification not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to update notification",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Notification marked read successfully", "notification", notification)
+}
+
+// MarkAllNotificationsRead marks every unread notification of the current
+// user read
+func (nc *NotificationsController) MarkAllNotificationsRead(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := database.DB.Collection("notifications").UpdateMany(ctx,
+		bson.M{"userId": c.Locals("userID").(string), "read": false},
+		bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}},
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to update notifications",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Notifications marked read successfully", "updated", result.ModifiedCount)
+}
+
+// DeleteNotification removes one of the current user's notifications
+func (nc *NotificationsController) DeleteNotification(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid notification ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": objID, "userId": c.Locals("userID").(string)}
+	result, err := database.DB.Collection("notifications").DeleteOne(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete notification",
+			Error:   err.Error(),
+		})
+	}
+
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Notification not found",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Notification deleted successfully", "", nil)
+}
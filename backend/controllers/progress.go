@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/handlers"
+	"toofy-backend/models"
+)
+
+// progressWriteInterval is how often a user's position in one episode is
+// stored; heartbeats in between are dropped
+const progressWriteInterval = 10 * time.Second
+
+type ProgressController struct {
+	// watchedThreshold is the share of an episode after which it is watched
+	watchedThreshold float64
+}
+
+func NewProgressController(cfg *config.Config) *ProgressController {
+	return &ProgressController{watchedThreshold: cfg.WatchedThreshold}
+}
+
+// SaveProgress records the current user's playback position in an episode.
+// Heartbeats closer together than progressWriteInterval are not stored.
+func (pc *ProgressController) SaveProgress(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.ProgressRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	role, _ := c.Locals("role").(string)
+	progress, err := pc.saveProgress(ctx, c.Locals("userID").(string), role, objID, &req)
+	if err != nil {
+		return errorResponse(c, err, "Failed to save progress")
+	}
+
+	return respond(c, fiber.StatusOK, "Progress saved successfully", "", fiber.Map{
+		"progress": progress,
+		"saved":    progress != nil,
+	})
+}
+
+// HandleProgressMessage handles "progress" websocket messages, the
+// websocket form of SaveProgress
+func (pc *ProgressController) HandleProgressMessage(userID, role string, payload []byte) error {
+	var req models.ProgressRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return err
+	}
+
+	objID, err := primitive.ObjectIDFromHex(req.EpisodeID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid episode ID")
+	}
+	if msg := req.Validate(); msg != "" {
+		return fiber.NewError(fiber.StatusBadRequest, msg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = pc.saveProgress(ctx, userID, role, objID, &req)
+	return err
+}
+
+// saveProgress stores a heartbeat and returns the stored progress, or nil if
+// the heartbeat came too soon after the previous one. Other devices of the
+// user are told about the new position.
+func (pc *ProgressController) saveProgress(ctx context.Context, userID, role string, episodeID primitive.ObjectID, req *models.ProgressRequest) (*models.WatchProgress, error) {
+	episode, err := findEpisode(ctx, episodeID)
+	if err != nil {
+		return nil, err
+	}
+	if !episodeVisibleTo(role, episode) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+
+	duration := req.Duration
+	if duration == 0 {
+		duration = float64(episode.Duration)
+	}
+	watched := duration > 0 && req.Position >= duration*pc.watchedThreshold
+	for _, marker := range episode.Markers {
+		if marker.Type == models.MarkerTypeEnding && req.Position >= marker.Start {
+			watched = true
+		}
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"animeId":   episode.AnimeID,
+		"position":  req.Position,
+		"duration":  duration,
+		"updatedAt": now,
+	}
+	update := bson.M{"$set": set}
+
+	// The throttle is part of the filter: a recent progress document does not
+	// match, and the upsert then fails on the unique index. Reaching the
+	// watched threshold is always stored.
+	recent := bson.M{"updatedAt": bson.M{"$lte": now.Add(-progressWriteInterval)}}
+	filter := bson.M{"userId": userID, "episodeId": episodeID}
+	if watched {
+		set["watched"] = true
+		filter["$or"] = bson.A{recent, bson.M{"watched": false}}
+	} else {
+		update["$setOnInsert"] = bson.M{"watched": false}
+		for key, value := range recent {
+			filter[key] = value
+		}
+	}
+
+	var progress models.WatchProgress
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = database.DB.Collection("watch_progress").FindOneAndUpdate(ctx, filter, update, opts).Decode(&progress)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	handlers.NotifyUsers([]string{userID}, "progress", progress)
+	return &progress, nil
+}
+
+// GetAnimeProgress returns the current user's progress in the episodes of an
+// anime, for marking watched episodes in the episode list
+func (pc *ProgressController) GetAnimeProgress(c *fiber.Ctx) error {
+	animeID := c.Query("animeId")
+	if animeID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "animeId is required",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": animeID}
+	cursor, err := database.DB.Collection("watch_progress").Find(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch progress",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var progress []models.WatchProgress
+	if err = cursor.All(ctx, &progress); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse progress",
+			Error:   err.Error(),
+		})
+	}
+
+	if progress == nil {
+		progress = []models.WatchProgress{}
+	}
+
+	return respond(c, fiber.StatusOK, "Progress retrieved successfully", "progress", progress)
+}
+
+// GetContinueWatching returns the episode to play next for each anime the
+// current user has played, most recently played first: the last played
+// episode at its position, or the episode after it once watched. Anime with
+// nothing left to play and dropped anime are left out.
+func (pc *ProgressController) GetContinueWatching(c *fiber.Ctx) error {
+	limitNum, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limitNum < 1 || limitNum > 50 {
+		limitNum = 20
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID := c.Locals("userID").(string)
+	role, _ := c.Locals("role").(string)
+
+	dropped, err := database.DB.Collection("library").Distinct(ctx, "animeId", bson.M{"userId": userID, "status": models.LibraryDropped})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch library",
+			Error:   err.Error(),
+		})
+	}
+	if dropped == nil {
+		dropped = []interface{}{}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID, "animeId": bson.M{"$nin": dropped}}}},
+		{{Key: "$sort", Value: bson.M{"updatedAt": -1}}},
+		{{Key: "$group", Value: bson.M{"_id": "$animeId", "progress": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$sort", Value: bson.M{"progress.updatedAt": -1}}},
+		{{Key: "$limit", Value: 100}},
+	}
+	cursor, err := database.DB.Collection("watch_progress").Aggregate(ctx, pipeline)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch progress",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var latest []struct {
+		Progress models.WatchProgress `bson:"progress"`
+	}
+	if err = cursor.All(ctx, &latest); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse progress",
+			Error:   err.Error(),
+		})
+	}
+
+	animeIDs := []string{}
+	for _, l := range latest {
+		animeIDs = append(animeIDs, l.Progress.AnimeID)
+	}
+	animes, err := findAnimeByHexIDs(ctx, animeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+
+	episodeIDs := []primitive.ObjectID{}
+	for _, l := range latest {
+		episodeIDs = append(episodeIDs, l.Progress.EpisodeID)
+	}
+	episodes, err := findEpisodesByID(ctx, episodeIDs, role)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch episodes",
+			Error:   err.Error(),
+		})
+	}
+
+	basePath := episodesPath(c)
+	items := []models.ContinueWatchingItem{}
+	for _, l := range latest {
+		if len(items) == limitNum {
+			break
+		}
+		progress := l.Progress
+		anime, ok := animes[progress.AnimeID]
+		if !ok {
+			continue
+		}
+		episode, ok := episodes[progress.EpisodeID]
+		if !ok {
+			continue
+		}
+
+		item := models.ContinueWatchingItem{
+			Anime:     anime.Ref(),
+			UpdatedAt: progress.UpdatedAt,
+		}
+		if progress.Watched {
+			next, err := nextEpisode(ctx, &episode, role)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+					Success: false,
+					Message: "Failed to fetch episodes",
+					Error:   err.Error(),
+				})
+			}
+			if next == nil {
+				continue
+			}
+			episode = *next
+		} else {
+			item.Position = progress.Position
+			item.Duration = progress.Duration
+		}
+		normalizeEpisode(&episode)
+		item.Episode = models.EpisodeRef{
+			ID:    episode.ID,
+			Label: episode.Label,
+			Title: episode.Title,
+			Href:  basePath + episode.ID.Hex(),
+		}
+		items = append(items, item)
+	}
+
+	return respond(c, fiber.StatusOK, "Continue watching retrieved successfully", "items", items)
+}
+
+// findEpisodesByID loads the episodes with the given IDs that are visible to
+// role, keyed by ID. Their numbering is left as stored.
+func findEpisodesByID(ctx context.Context, ids []primitive.ObjectID, role string) (map[primitive.ObjectID]models.Episode, error) {
+	episodes := map[primitive.ObjectID]models.Episode{}
+	if len(ids) == 0 {
+		return episodes, nil
+	}
+
+	filter := withEpisodeVisibility(bson.M{"_id": bson.M{"$in": ids}}, role)
+	cursor, err := database.DB.Collection("episodes").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Episode
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, episode := range found {
+		episodes[episode.ID] = episode
+	}
+	return episodes, nil
+}
+
+// nextEpisode returns the episode after episode in display order among those
+// visible to role, or nil for the last one
+func nextEpisode(ctx context.Context, episode *models.Episode, role string) (*models.Episode, error) {
+	after := bson.A{
+		bson.M{"type": bson.M{"$gt": episode.Type}},
+		bson.M{"type": episode.Type, "season": bson.M{"$gt": episode.Season}},
+		bson.M{"type": episode.Type, "season": episode.Season, "number": bson.M{"$gt": episode.Number}},
+	}
+
+	filter := withEpisodeVisibility(bson.M{"animeId": episode.AnimeID, "$or": after}, role)
+	opts := options.FindOne().SetSort(models.EpisodeSort)
+	var next models.Episode
+	err := database.DB.Collection("episodes").FindOne(ctx, filter, opts).Decode(&next)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
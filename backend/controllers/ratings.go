@@ -0,0 +1,424 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// rankInterval is how often the weighted ratings of every anime are
+// recomputed against the current mean score of the site
+const rankInterval = time.Hour
+
+// ratingPrior holds what weighted ratings are pulled towards: anime with few
+// votes rank close to the mean score of the site, until they reach about
+// minVotes votes
+var ratingPrior = struct {
+	sync.RWMutex
+	mean     float64
+	minVotes int
+}{minVotes: 10}
+
+type RatingsController struct{}
+
+func NewRatingsController() *RatingsController {
+	return &RatingsController{}
+}
+
+// StartRatingRanker keeps the site mean score up to date and recomputes the
+// weighted ratings of every anime with it
+func StartRatingRanker(cfg *config.Config) {
+	ratingPrior.Lock()
+	ratingPrior.minVotes = cfg.RatingMinVotes
+	ratingPrior.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(rankInterval)
+		defer ticker.Stop()
+
+		for {
+			rankAnime()
+			<-ticker.C
+		}
+	}()
+}
+
+func rankAnime() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"rating.count": bson.M{"$gt": 0}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"sum":   bson.M{"$sum": "$rating.sum"},
+			"count": bson.M{"$sum": "$rating.count"},
+		}}},
+	}
+	cursor, err := database.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		fmt.Printf("Warning: Failed to compute the mean score: %v\n", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		Sum   int `bson:"sum"`
+		Count int `bson:"count"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		fmt.Printf("Warning: Failed to compute the mean score: %v\n", err)
+		return
+	}
+	if len(totals) == 0 || totals[0].Count == 0 {
+		return
+	}
+
+	ratingPrior.Lock()
+	ratingPrior.mean = float64(totals[0].Sum) / float64(totals[0].Count)
+	ratingPrior.Unlock()
+
+	if err := updateRatingScores(ctx, bson.M{"rating.count": bson.M{"$gt": 0}}); err != nil {
+		fmt.Printf("Warning: Failed to update weighted ratings: %v\n", err)
+	}
+}
+
+// updateRatingScores recomputes the mean and weighted rating of the matching
+// anime from their vote count and sum
+func updateRatingScores(ctx context.Context, filter bson.M) error {
+	ratingPrior.RLock()
+	prior, minVotes := ratingPrior.mean, ratingPrior.minVotes
+	ratingPrior.RUnlock()
+
+	pipeline := bson.A{bson.M{"$set": ratingScores(prior, minVotes)}}
+	_, err := database.DB.Collection("anime").UpdateMany(ctx, filter, pipeline)
+	return err
+}
+
+// ratingScores returns the fields of the rating mean and weighted rating, as
+// expressions over the vote count and sum of an anime
+func ratingScores(prior float64, minVotes int) bson.M {
+	m := float64(minVotes)
+	hasVotes := bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$rating.count", 0}}, 0}}
+	return bson.M{
+		"rating.mean": bson.M{"$cond": bson.A{
+			hasVotes,
+			bson.M{"$divide": bson.A{"$rating.sum", "$rating.count"}},
+			0,
+		}},
+		// (sum + m*C) / (count + m), the mean shrunk towards the site mean
+		"rating.weighted": bson.M{"$cond": bson.A{
+			hasVotes,
+			bson.M{"$divide": bson.A{
+				bson.M{"$add": bson.A{"$rating.sum", m * prior}},
+				bson.M{"$add": bson.A{"$rating.count", m}},
+			}},
+			0,
+		}},
+	}
+}
+
+// applyRatingChange updates the rating aggregate of an anime for one user
+// changing their score from oldScore to newScore; 0 means no rating
+func applyRatingChange(ctx context.Context, animeID string, oldScore, newScore int) error {
+	if oldScore == newScore {
+		return nil
+	}
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return err
+	}
+
+	inc := bson.M{"rating.sum": newScore - oldScore}
+	if oldScore > 0 {
+		inc["rating.distribution."+strconv.Itoa(oldScore)] = -1
+	} else {
+		inc["rating.count"] = 1
+	}
+	if newScore > 0 {
+		inc["rating.distribution."+strconv.Itoa(newScore)] = 1
+	} else {
+		inc["rating.count"] = -1
+	}
+
+	_, err = database.DB.Collection("anime").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	return updateRatingScores(ctx, bson.M{"_id": objID})
+}
+
+// recomputeAnimeRatings rebuilds the rating aggregates of anime from the
+// ratings collection, after ratings were moved or deleted in bulk
+func recomputeAnimeRatings(ctx context.Context, animeIDs ...string) error {
+	for _, animeID := range animeIDs {
+		objID, err := primitive.ObjectIDFromHex(animeID)
+		if err != nil {
+			continue
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"animeId": animeID}}},
+			{{Key: "$group", Value: bson.M{"_id": "$score", "count": bson.M{"$sum": 1}}}},
+		}
+		cursor, err := database.DB.Collection("ratings").Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		var scores []struct {
+			Score int `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		err = cursor.All(ctx, &scores)
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+
+		rating := models.AnimeRating{Distribution: map[string]int{}}
+		for _, s := range scores {
+			rating.Count += s.Count
+			rating.Sum += s.Score * s.Count
+			rating.Distribution[strconv.Itoa(s.Score)] = s.Count
+		}
+		_, err = database.DB.Collection("anime").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"rating": rating}})
+		if err != nil {
+			return err
+		}
+		if err := updateRatingScores(ctx, bson.M{"_id": objID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRating stores a user's score for an anime, updates the anime's rating
+// aggregate and shows the score on the user's library entry
+func setRating(ctx context.Context, userID, animeID string, score int) (*models.Rating, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set":         bson.M{"score": score, "updatedAt": now},
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+
+	var previous models.Rating
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err := database.DB.Collection("ratings").FindOneAndUpdate(ctx, bson.M{"userId": userID, "animeId": animeID}, update, opts).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	if err := applyRatingChange(ctx, animeID, previous.Score, score); err != nil {
+		return nil, err
+	}
+	if err := setLibraryScore(ctx, userID, animeID, score); err != nil {
+		return nil, err
+	}
+	// A review shows its author's current score
+	if _, err := database.DB.Collection("reviews").UpdateOne(ctx, bson.M{"userId": userID, "animeId": animeID}, bson.M{"$set": bson.M{"score": score}}); err != nil {
+		return nil, err
+	}
+
+	var rating models.Rating
+	err = database.DB.Collection("ratings").FindOne(ctx, bson.M{"userId": userID, "animeId": animeID}).Decode(&rating)
+	return &rating, err
+}
+
+// removeRating deletes a user's score for an anime. It reports whether the
+// user had rated the anime.
+func removeRating(ctx context.Context, userID, animeID string) (bool, error) {
+	var previous models.Rating
+	err := database.DB.Collection("ratings").FindOneAndDelete(ctx, bson.M{"userId": userID, "animeId": animeID}).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := applyRatingChange(ctx, animeID, previous.Score, 0); err != nil {
+		return true, err
+	}
+	return true, setLibraryScore(ctx, userID, animeID, 0)
+}
+
+func setLibraryScore(ctx context.Context, userID, animeID string, score int) error {
+	_, err := database.DB.Collection("library").UpdateOne(ctx,
+		bson.M{"userId": userID, "animeId": animeID},
+		bson.M{"$set": bson.M{"score": score}},
+	)
+	return err
+}
+
+// GetMyRating returns the current user's score for an anime
+func (rc *RatingsController) GetMyRating(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rating models.Rating
+	filter := bson.M{"userId": c.Locals("userID").(string), "animeId": c.Params("id")}
+	err := database.DB.Collection("ratings").FindOne(ctx, filter).Decode(&rating)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You have not rated this anime",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch rating",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Rating retrieved successfully", "rating", rating)
+}
+
+// RateAnime sets the current user's score for an anime
+func (rc *RatingsController) RateAnime(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	var req models.RatingRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := database.DB.Collection("anime").CountDocuments(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	if count == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+
+	rating, err := setRating(ctx, c.Locals("userID").(string), objID.Hex(), req.Score)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to save rating",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Rating saved successfully", "rating", rating)
+}
+
+// DeleteRating removes the current user's score for an anime
+func (rc *RatingsController) DeleteRating(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	removed, err := removeRating(ctx, c.Locals("userID").(string), c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete rating",
+			Error:   err.Error(),
+		})
+	}
+
+	if !removed {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You have not rated this anime",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Rating deleted successfully", "", nil)
+}
+
+// GetTopRated lists rated anime by weighted rating, highest first
+func (rc *RatingsController) GetTopRated(c *fiber.Ctx) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "30"))
+	if err != nil || limitNum < 1 || limitNum > 100 {
+		limitNum = 30
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	animeCollection := database.DB.Collection("anime")
+	filter := bson.M{"rating.count": bson.M{"$gt": 0}}
+
+	total, err := animeCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count anime",
+			Error:   err.Error(),
+		})
+	}
+
+	opts := options.Find().
+		SetSkip(int64((pageNum - 1) * limitNum)).
+		SetLimit(int64(limitNum)).
+		SetSort(bson.D{{Key: "rating.weighted", Value: -1}, {Key: "rating.count", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := animeCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var animes []models.Anime
+	if err = cursor.All(ctx, &animes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime",
+			Error:   err.Error(),
+		})
+	}
+
+	if animes == nil {
+		animes = []models.Anime{}
+	}
+
+	return respondPage(c, "Top rated anime retrieved successfully", "data", animes, pageMeta(total, pageNum, limitNum))
+}
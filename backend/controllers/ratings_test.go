@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// evalExpr evaluates the aggregation operators ratingScores uses against doc
+func evalExpr(t *testing.T, expr interface{}, doc bson.M) interface{} {
+	t.Helper()
+	switch e := expr.(type) {
+	case string:
+		if !strings.HasPrefix(e, "$") {
+			return e
+		}
+		var value interface{} = doc
+		for _, key := range strings.Split(e[1:], ".") {
+			m, ok := value.(bson.M)
+			if !ok {
+				return nil
+			}
+			value = m[key]
+		}
+		if n, ok := value.(int); ok {
+			return float64(n)
+		}
+		return value
+	case int:
+		return float64(e)
+	case float64:
+		return e
+	case bson.M:
+		for op, raw := range e {
+			args := raw.(bson.A)
+			number := func(i int) float64 {
+				v, ok := evalExpr(t, args[i], doc).(float64)
+				if !ok {
+					t.Fatalf("%s argument %d is not a number", op, i)
+				}
+				return v
+			}
+			switch op {
+			case "$ifNull":
+				if v := evalExpr(t, args[0], doc); v != nil {
+					return v
+				}
+				return evalExpr(t, args[1], doc)
+			case "$gt":
+				return number(0) > number(1)
+			case "$cond":
+				if evalExpr(t, args[0], doc).(bool) {
+					return evalExpr(t, args[1], doc)
+				}
+				return evalExpr(t, args[2], doc)
+			case "$divide":
+				return number(0) / number(1)
+			case "$add":
+				return number(0) + number(1)
+			}
+			t.Fatalf("unsupported operator %s", op)
+		}
+	}
+	t.Fatalf("unsupported expression %#v", expr)
+	return nil
+}
+
+func TestRatingScores(t *testing.T) {
+	tests := []struct {
+		name         string
+		prior        float64
+		minVotes     int
+		rating       bson.M // nil when the anime was never rated
+		wantMean     float64
+		wantWeighted float64
+	}{
+		{name: "never rated", prior: 7, minVotes: 10, wantMean: 0, wantWeighted: 0},
+		{name: "all votes removed", prior: 7, minVotes: 10, rating: bson.M{"sum": 0, "count": 0}},
+		{name: "single top vote", prior: 7, minVotes: 10, rating: bson.M{"sum": 10, "count": 1}, wantMean: 10, wantWeighted: 80.0 / 11},
+		{name: "single low vote", prior: 7, minVotes: 10, rating: bson.M{"sum": 1, "count": 1}, wantMean: 1, wantWeighted: 71.0 / 11},
+		{name: "halfway at minVotes", prior: 7, minVotes: 10, rating: bson.M{"sum": 90, "count": 10}, wantMean: 9, wantWeighted: 8},
+		{name: "many votes", prior: 7, minVotes: 10, rating: bson.M{"sum": 9000, "count": 1000}, wantMean: 9, wantWeighted: 9070.0 / 1010},
+		{name: "no prior weight", prior: 7, minVotes: 0, rating: bson.M{"sum": 17, "count": 2}, wantMean: 8.5, wantWeighted: 8.5},
+		{name: "site mean not computed yet", prior: 0, minVotes: 10, rating: bson.M{"sum": 90, "count": 10}, wantMean: 9, wantWeighted: 4.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := bson.M{}
+			if tt.rating != nil {
+				doc["rating"] = tt.rating
+			}
+			scores := ratingScores(tt.prior, tt.minVotes)
+
+			mean := evalExpr(t, scores["rating.mean"], doc).(float64)
+			weighted := evalExpr(t, scores["rating.weighted"], doc).(float64)
+			if math.Abs(mean-tt.wantMean) > 1e-9 {
+				t.Errorf("mean = %v, want %v", mean, tt.wantMean)
+			}
+			if math.Abs(weighted-tt.wantWeighted) > 1e-9 {
+				t.Errorf("weighted = %v, want %v", weighted, tt.wantWeighted)
+			}
+		})
+	}
+}
+
+func TestRatingScoresRanking(t *testing.T) {
+	scores := ratingScores(7, 10)
+	weighted := func(sum, count int) float64 {
+		return evalExpr(t, scores["rating.weighted"], bson.M{"rating": bson.M{"sum": sum, "count": count}}).(float64)
+	}
+
+	// A single perfect score must not outrank a well reviewed anime
+	if few, many := weighted(10, 1), weighted(900, 100); few >= many {
+		t.Errorf("weighted(1 vote of 10) = %v, want below weighted(100 votes of 9) = %v", few, many)
+	}
+	// A single low score pulls the rating below the site mean, but not far
+	if low := weighted(3, 1); low >= 7 || low < 6 {
+		t.Errorf("weighted(1 vote of 3) = %v, want just below the site mean 7", low)
+	}
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/models"
+)
+
+// isV2 reports whether the request came in on /api/v2, whose responses are
+// models.Envelope
+func isV2(c *fiber.Ctx) bool {
+	return c.Locals("apiVersion") == "v2"
+}
+
+// episodesPath returns the prefix of episode links in a response, on the API
+// version the request came in on
+func episodesPath(c *fiber.Ctx) string {
+	if isV2(c) {
+		return "/api/v2/episodes/"
+	}
+	return "/api/episodes/"
+}
+
+// respond sends a successful response. On /api the value is wrapped in an
+// object under key in data, or is data itself when key is empty; /api/v2 puts
+// the value directly in the envelope's data.
+func respond(c *fiber.Ctx, status int, message, key string, value interface{}) error {
+	if isV2(c) {
+		return c.Status(status).JSON(models.Envelope{
+			Success: true,
+			Message: message,
+			Data:    value,
+		})
+	}
+
+	body := fiber.Map{"success": true}
+	if message != "" {
+		body["message"] = message
+	}
+	if key != "" {
+		body["data"] = fiber.Map{key: value}
+	} else if value != nil {
+		body["data"] = value
+	}
+	return c.Status(status).JSON(body)
+}
+
+// respondPage sends a page of a list. On /api the items are under key in data
+// next to the paging fields; /api/v2 puts the items in data and the paging in
+// meta.
+func respondPage(c *fiber.Ctx, message, key string, items interface{}, meta models.PageMeta) error {
+	if isV2(c) {
+		return c.Status(fiber.StatusOK).JSON(models.Envelope{
+			Success: true,
+			Message: message,
+			Data:    items,
+			Meta:    &meta,
+		})
+	}
+
+	data := fiber.Map{
+		key:           items,
+		"total":       meta.Total,
+		"page":        meta.Page,
+		"limit":       meta.Limit,
+		"total_pages": meta.TotalPages,
+	}
+	return respond(c, fiber.StatusOK, message, "", data)
+}
+
+// pageMeta describes a page of a list of total items
+func pageMeta(total int64, page, limit int) models.PageMeta {
+	return models.PageMeta{
+		Total:      int(total),
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (int(total) + limit - 1) / limit,
+	}
+}
+
+// errorResponse sends the status and message of a *fiber.Error returned by a
+// helper, or a 500 with message for any other error
+func errorResponse(c *fiber.Ctx, err error, message string) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(models.ErrorResponse{
+			Success: false,
+			Message: fiberErr.Message,
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+		Success: false,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
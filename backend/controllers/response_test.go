@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"toofy-backend/middleware"
+	"toofy-backend/models"
+)
+
+func TestRespondVersions(t *testing.T) {
+	animeID, _ := primitive.ObjectIDFromHex("64b7f0c2a1b2c3d4e5f60718")
+	anime := models.Anime{ID: animeID, Title: "Frieren", EpisodeCount: 28}
+	// Larger than float64 can hold exactly
+	slider := []SliderItem{{ID: "s1", AnimeID: animeID.Hex(), Order: 9007199254740993}}
+
+	sliderHandler := func(c *fiber.Ctx) error {
+		return respond(c, fiber.StatusOK, "", "", slider)
+	}
+	animeHandler := func(c *fiber.Ctx) error {
+		return respond(c, fiber.StatusOK, "Anime retrieved successfully", "anime", anime)
+	}
+	animeListHandler := func(c *fiber.Ctx) error {
+		return respondPage(c, "Anime retrieved successfully", "data", []models.Anime{anime}, pageMeta(41, 2, 20))
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		handler  fiber.Handler
+		wantData string // prefix of the data value
+		wantHas  string // also somewhere in data
+		wantMeta *models.PageMeta
+	}{
+		{
+			name:     "slider v1",
+			path:     "/api/slider",
+			handler:  sliderHandler,
+			wantData: `[{"id":"s1","animeId":"64b7f0c2a1b2c3d4e5f60718"`,
+			wantHas:  `"order":9007199254740993`,
+		},
+		{
+			name:     "slider v2",
+			path:     "/api/v2/slider",
+			handler:  sliderHandler,
+			wantData: `[{"id":"s1","animeId":"64b7f0c2a1b2c3d4e5f60718"`,
+			wantHas:  `"order":9007199254740993`,
+		},
+		{
+			name:     "anime by id v1",
+			path:     "/api/anime/64b7f0c2a1b2c3d4e5f60718",
+			handler:  animeHandler,
+			wantData: `{"anime":{"id":"64b7f0c2a1b2c3d4e5f60718","title":"Frieren"`,
+			wantHas:  `"episodeCount":28,`,
+		},
+		{
+			name:     "anime by id v2",
+			path:     "/api/v2/anime/64b7f0c2a1b2c3d4e5f60718",
+			handler:  animeHandler,
+			wantData: `{"id":"64b7f0c2a1b2c3d4e5f60718","title":"Frieren"`,
+			wantHas:  `"episodeCount":28,`,
+		},
+		{
+			name:     "anime list v1",
+			path:     "/api/anime",
+			handler:  animeListHandler,
+			wantData: `{"data":[{"id":"64b7f0c2a1b2c3d4e5f60718"`,
+			wantHas:  `"limit":20,"page":2,"total":41,"total_pages":3`,
+		},
+		{
+			name:     "anime list v2",
+			path:     "/api/v2/anime",
+			handler:  animeListHandler,
+			wantData: `[{"id":"64b7f0c2a1b2c3d4e5f60718"`,
+			wantMeta: &models.PageMeta{Total: 41, Page: 2, Limit: 20, TotalPages: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/api/v2/*", middleware.V2Envelope(), tt.handler)
+			app.Get("/api/*", tt.handler)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+
+			var body struct {
+				Success bool             `json:"success"`
+				Data    json.RawMessage  `json:"data"`
+				Meta    *models.PageMeta `json:"meta"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if !body.Success {
+				t.Errorf("success = false, want true")
+			}
+			if data := string(body.Data); !strings.HasPrefix(data, tt.wantData) {
+				t.Errorf("data = %s, want prefix %s", data, tt.wantData)
+			}
+			if data := string(body.Data); !strings.Contains(data, tt.wantHas) {
+				t.Errorf("data = %s, want it to contain %s", data, tt.wantHas)
+			}
+			if tt.wantMeta == nil && body.Meta != nil {
+				t.Errorf("meta = %+v, want none", *body.Meta)
+			}
+			if tt.wantMeta != nil && (body.Meta == nil || *body.Meta != *tt.wantMeta) {
+				t.Errorf("meta = %+v, want %+v", body.Meta, *tt.wantMeta)
+			}
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+type ReviewsController struct {
+	// editWindow is how long after writing a review its author can edit it
+	editWindow time.Duration
+}
+
+func NewReviewsController(cfg *config.Config) *ReviewsController {
+	return &ReviewsController{editWindow: cfg.ReviewEditWindow}
+}
+
+// GetAnimeReviews lists the reviews of an anime, most helpful or most recent
+// first. Reviews removed by moderators are left out.
+func (rc *ReviewsController) GetAnimeReviews(c *fiber.Ctx) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limitNum < 1 || limitNum > 50 {
+		limitNum = 20
+	}
+
+	sort := bson.D{{Key: "helpfulCount", Value: -1}, {Key: "createdAt", Value: -1}}
+	switch c.Query("sort", models.ReviewSortHelpful) {
+	case models.ReviewSortHelpful:
+	case models.ReviewSortRecent:
+		sort = bson.D{{Key: "createdAt", Value: -1}}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid sort. Must be: helpful or recent",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviewsCollection := database.DB.Collection("reviews")
+	filter := bson.M{"animeId": c.Params("id"), "removed": false}
+
+	total, err := reviewsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count reviews",
+			Error:   err.Error(),
+		})
+	}
+
+	opts := options.Find().
+		SetSkip(int64((pageNum - 1) * limitNum)).
+		SetLimit(int64(limitNum)).
+		SetSort(append(sort, bson.E{Key: "_id", Value: -1}))
+	cursor, err := reviewsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch reviews",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var reviews []models.Review
+	if err = cursor.All(ctx, &reviews); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse reviews",
+			Error:   err.Error(),
+		})
+	}
+
+	if reviews == nil {
+		reviews = []models.Review{}
+	}
+
+	return respondPage(c, "Reviews retrieved successfully", "reviews", reviews, pageMeta(total, pageNum, limitNum))
+}
+
+// CreateReview adds the current user's review of an anime. Its score also
+// becomes the user's rating of the anime.
+func (rc *ReviewsController) CreateReview(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	var req models.AnimeReviewRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := database.DB.Collection("anime").CountDocuments(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	if count == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+
+	userID := c.Locals("userID").(string)
+	userObjID, _ := primitive.ObjectIDFromHex(userID)
+	var author models.User
+	if err := database.DB.Collection("users").FindOne(ctx, bson.M{"_id": userObjID}).Decode(&author); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch user",
+			Error:   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	review := models.Review{
+		AnimeID:    objID.Hex(),
+		UserID:     userID,
+		AuthorName: author.DisplayName,
+		Score:      req.Score,
+		Title:      req.Title,
+		Body:       req.Body,
+		Spoiler:    req.Spoiler,
+		HelpfulBy:  []string{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	result, err := database.DB.Collection("reviews").InsertOne(ctx, review)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You already reviewed this anime",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to create review",
+			Error:   err.Error(),
+		})
+	}
+	review.ID = result.InsertedID.(primitive.ObjectID)
+
+	if _, err := setRating(ctx, userID, review.AnimeID, review.Score); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to save rating",
+			Error:   err.Error(),
+		})
+	}
+
+	rc.setEditableTo(&review)
+
+	return respond(c, fiber.StatusCreated, "Review created successfully", "review", review)
+}
+
+// UpdateReview edits the current user's review while its edit window is
+// open
+func (rc *ReviewsController) UpdateReview(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid review ID",
+		})
+	}
+
+	var req models.AnimeReviewRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := c.Locals("userID").(string)
+	review, err := findOwnReview(ctx, objID, userID)
+	if err != nil {
+		return errorResponse(c, err, "Failed to fetch review")
+	}
+
+	if review.Removed {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "This review was removed by a moderator",
+		})
+	}
+	if time.Since(review.CreatedAt) > rc.editWindow {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "The edit window of this review has closed",
+		})
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"score":     req.Score,
+			"title":     req.Title,
+			"body":      req.Body,
+			"spoiler":   req.Spoiler,
+			"updatedAt": time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("reviews").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(review)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to update review",
+			Error:   err.Error(),
+		})
+	}
+
+	if _, err := setRating(ctx, userID, review.AnimeID, review.Score); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to save rating",
+			Error:   err.Error(),
+		})
+	}
+
+	rc.setEditableTo(review)
+
+	return respond(c, fiber.StatusOK, "Review updated successfully", "review", review)
+}
+
+// DeleteReview deletes the current user's review. Their rating of the anime
+// is kept.
+func (rc *ReviewsController) DeleteReview(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid review ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": objID, "userId": c.Locals("userID").(string)}
+	result, err := database.DB.Collection("reviews").DeleteOne(ctx, filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete review",
+			Error:   err.Error(),
+		})
+	}
+
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Review not found",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Review deleted successfully", "", nil)
+}
+
+// RemoveReview hides a review as a moderator. The review is kept, with who
+// removed it and why.
+func (rc *ReviewsController) RemoveReview(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid review ID",
+		})
+	}
+
+	var req models.RemoveReviewRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Reason is required",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"removed":      true,
+			"removedBy":    c.Locals("userID").(string),
+			"removeReason": req.Reason,
+		},
+	}
+
+	var review models.Review
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = database.DB.Collection("reviews").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Review not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to remove review",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Review removed successfully", "review", review)
+}
+
+// MarkReviewHelpful records that the current user found a review helpful
+func (rc *ReviewsController) MarkReviewHelpful(c *fiber.Ctx) error {
+	return rc.setHelpful(c, true)
+}
+
+// UnmarkReviewHelpful withdraws the current user's helpful vote on a review
+func (rc *ReviewsController) UnmarkReviewHelpful(c *fiber.Ctx) error {
+	return rc.setHelpful(c, false)
+}
+
+func (rc *ReviewsController) setHelpful(c *fiber.Ctx, helpful bool) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid review ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reviewsCollection := database.DB.Collection("reviews")
+	userID := c.Locals("userID").(string)
+
+	var review models.Review
+	err = reviewsCollection.FindOne(ctx, bson.M{"_id": objID, "removed": false}).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Review not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch review",
+			Error:   err.Error(),
+		})
+	}
+
+	if review.UserID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You cannot vote on your own review",
+		})
+	}
+
+	// The filter on helpfulBy keeps the count in step with the voters when
+	// the same vote arrives twice
+	filter := bson.M{"_id": objID, "helpfulBy": bson.M{"$ne": userID}}
+	update := bson.M{"$addToSet": bson.M{"helpfulBy": userID}, "$inc": bson.M{"helpfulCount": 1}}
+	if !helpful {
+		filter["helpfulBy"] = userID
+		update = bson.M{"$pull": bson.M{"helpfulBy": userID}, "$inc": bson.M{"helpfulCount": -1}}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = reviewsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&review)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to record vote",
+			Error:   err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Vote recorded successfully", "review", review)
+}
+
+// setEditableTo shows the author until when the review can be edited
+func (rc *ReviewsController) setEditableTo(review *models.Review) {
+	editableTo := review.CreatedAt.Add(rc.editWindow)
+	review.EditableTo = &editableTo
+}
+
+func findOwnReview(ctx context.Context, objID primitive.ObjectID, userID string) (*models.Review, error) {
+	var review models.Review
+	err := database.DB.Collection("reviews").FindOne(ctx, bson.M{"_id": objID, "userId": userID}).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Review not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// Description lengths used by search engines and social cards
+const (
+	metaDescriptionLength   = 160
+	socialDescriptionLength = 200
+	siteName                = "Toofy"
+	siteLocale              = "ar_AR"
+)
+
+type SeoController struct {
+	siteURL string
+	upload  *UploadController
+}
+
+func NewSeoController(cfg *config.Config, upload *UploadController) *SeoController {
+	return &SeoController{
+		siteURL: strings.TrimRight(cfg.SiteURL, "/"),
+		upload:  upload,
+	}
+}
+
+// animePageURL returns the public page URL of an anime
+func animePageURL(siteURL, slug string) string {
+	return fmt.Sprintf("%s/anime/%s", siteURL, slug)
+}
+
+// GetAnimeMeta returns JSON-LD, OpenGraph and Twitter card metadata for an anime page
+func (sc *SeoController) GetAnimeMeta(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid anime ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var anime models.Anime
+	err = database.DB.Collection("anime").FindOne(ctx, bson.M{"_id": objID}).Decode(&anime)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Anime not found",
+		})
+	}
+
+	canonical := animePageURL(sc.siteURL, anime.Slug)
+	if anime.Slug == "" {
+		canonical = animePageURL(sc.siteURL, anime.ID.Hex())
+	}
+
+	title := anime.Title
+	if anime.SeasonYear != 0 {
+		title = fmt.Sprintf("%s (%d)", anime.Title, anime.SeasonYear)
+	}
+	title += " | " + siteName
+
+	socialDescription := utils.Truncate(anime.Description, socialDescriptionLength)
+
+	meta := models.SeoMeta{
+		Title:        title,
+		Description:  utils.Truncate(anime.Description, metaDescriptionLength),
+		CanonicalURL: canonical,
+		OpenGraph: models.OpenGraphMeta{
+			Title:       anime.Title,
+			Type:        "video.tv_show",
+			URL:         canonical,
+			Description: socialDescription,
+			SiteName:    siteName,
+			Locale:      siteLocale,
+		},
+		Twitter: models.TwitterMeta{
+			Card:        "summary",
+			Title:       anime.Title,
+			Description: socialDescription,
+		},
+	}
+	if anime.Type == "Movie" {
+		meta.OpenGraph.Type = "video.movie"
+	}
+
+	if anime.CoverUrl != "" {
+		image := &models.SeoImage{URL: anime.CoverUrl, Alt: anime.Title}
+		// Dimensions are best effort; only our own uploads can be measured
+		if strings.Contains(anime.CoverUrl, "/api/upload/image/") {
+			if width, height, err := sc.upload.ImageSize(anime.CoverUrl); err == nil {
+				image.Width, image.Height = width, height
+			}
+		}
+		meta.Image = image
+
+		meta.OpenGraph.Image = image.URL
+		meta.OpenGraph.ImageWidth = image.Width
+		meta.OpenGraph.ImageHeight = image.Height
+		meta.OpenGraph.ImageAlt = image.Alt
+		meta.Twitter.Card = "summary_large_image"
+		meta.Twitter.Image = image.URL
+		meta.Twitter.ImageAlt = image.Alt
+	}
+
+	meta.JSONLD = animeJSONLD(&anime, canonical, meta.Image)
+
+	return respond(c, fiber.StatusOK, "Anime metadata retrieved successfully", "meta", meta)
+}
+
+// animeJSONLD builds a schema.org TVSeries (or Movie) description of an anime
+func animeJSONLD(anime *models.Anime, canonical string, image *models.SeoImage) map[string]interface{} {
+	ld := map[string]interface{}{
+		"@context":    "https://schema.org",
+		"@type":       "TVSeries",
+		"name":        anime.Title,
+		"url":         canonical,
+		"description": utils.Truncate(anime.Description, 5000),
+	}
+	if anime.Type == "Movie" {
+		ld["@type"] = "Movie"
+	}
+
+	if len(anime.AlternativeNames) > 0 {
+		ld["alternateName"] = anime.AlternativeNames
+	}
+	if len(anime.Genres) > 0 {
+		ld["genre"] = anime.Genres
+	}
+	if anime.Studio != "" {
+		ld["productionCompany"] = map[string]interface{}{
+			"@type": "Organization",
+			"name":  anime.Studio,
+		}
+	}
+	if anime.SeasonYear != 0 {
+		year := strconv.Itoa(anime.SeasonYear)
+		if anime.Type == "Movie" {
+			ld["datePublished"] = year
+		} else {
+			ld["startDate"] = year
+		}
+	}
+	if anime.Type != "Movie" && anime.EpisodeCount > 0 {
+		ld["numberOfEpisodes"] = anime.EpisodeCount
+	}
+	if !anime.UpdatedAt.IsZero() {
+		ld["dateModified"] = anime.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	if image != nil {
+		imageObject := map[string]interface{}{
+			"@type": "ImageObject",
+			"url":   image.URL,
+		}
+		if image.Width > 0 && image.Height > 0 {
+			imageObject["width"] = image.Width
+			imageObject["height"] = image.Height
+		}
+		ld["image"] = imageObject
+	}
+
+	return ld
+}
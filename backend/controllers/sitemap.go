@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+// sitemapPageSize is the maximum number of URLs in a single sitemap, as
+// allowed by the sitemap protocol
+const sitemapPageSize = 50000
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	NS      string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	NS       string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapFilter matches the anime that have a public page
+var sitemapFilter = bson.M{"slug": bson.M{"$nin": bson.A{"", nil}}}
+
+// GetSitemap returns the anime sitemap, or a sitemap index pointing at paged
+// sitemaps once the catalog outgrows a single file
+func (fc *FeedsController) GetSitemap(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	total, err := database.DB.Collection("anime").CountDocuments(ctx, sitemapFilter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to count anime",
+			Error:   err.Error(),
+		})
+	}
+
+	if total <= sitemapPageSize {
+		return fc.sendSitemapPage(c, ctx, 1)
+	}
+
+	pages := int((total + sitemapPageSize - 1) / sitemapPageSize)
+	index := sitemapIndex{NS: sitemapNS}
+	for page := 1; page <= pages; page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+			Loc:     fmt.Sprintf("%s/sitemaps/anime-%d.xml", fc.baseURL, page),
+			LastMod: time.Now().UTC().Format("2006-01-02"),
+		})
+	}
+
+	return sendXML(c, "application/xml; charset=utf-8", index)
+}
+
+// GetSitemapPage returns one page of the anime sitemap
+func (fc *FeedsController) GetSitemapPage(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Params("page"))
+	if err != nil || page < 1 {
+		return fiber.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return fc.sendSitemapPage(c, ctx, page)
+}
+
+func (fc *FeedsController) sendSitemapPage(c *fiber.Ctx, ctx context.Context, page int) error {
+	opts := options.Find().
+		SetSort(bson.M{"_id": 1}).
+		SetSkip(int64((page - 1) * sitemapPageSize)).
+		SetLimit(sitemapPageSize).
+		SetProjection(bson.M{"slug": 1, "updatedAt": 1})
+
+	cursor, err := database.DB.Collection("anime").Find(ctx, sitemapFilter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch anime",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var animes []models.Anime
+	if err = cursor.All(ctx, &animes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse anime",
+			Error:   err.Error(),
+		})
+	}
+
+	if len(animes) == 0 && page > 1 {
+		return fiber.ErrNotFound
+	}
+
+	urlSet := sitemapURLSet{NS: sitemapNS}
+	for _, anime := range animes {
+		url := sitemapURL{Loc: fc.animeURL(anime.Slug)}
+		if !anime.UpdatedAt.IsZero() {
+			url.LastMod = anime.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+
+	return sendXML(c, "application/xml; charset=utf-8", urlSet)
+}
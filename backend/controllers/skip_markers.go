@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+const (
+	// maxMarkerSubmissionsPerHour limits how many markers one user can submit per hour
+	maxMarkerSubmissionsPerHour = 20
+)
+
+// ReplaceMarkers sets the skip markers of an episode, at most one per type.
+// Markers set here are editor markers, which votes never replace.
+func (ec *EpisodesController) ReplaceMarkers(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.MarkersRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	markers := req.Markers
+	if markers == nil {
+		markers = []models.SkipMarker{}
+	}
+	seen := map[string]bool{}
+	for i := range markers {
+		if msg := markers[i].Validate(episode.Duration); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: fmt.Sprintf("Marker %d: %s", i+1, msg),
+			})
+		}
+		if seen[markers[i].Type] {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Duplicate marker type " + markers[i].Type,
+			})
+		}
+		seen[markers[i].Type] = true
+		markers[i].Source = models.MarkerSourceEditor
+	}
+	models.SortMarkers(markers)
+
+	if err := saveMarkers(ctx, objID, markers); err != nil {
+		return episodeErrorResponse(c, err, "Failed to update markers")
+	}
+
+	return respond(c, fiber.StatusOK, "Markers updated successfully", "markers", markers)
+}
+
+// SubmitMarker records a skip marker proposed by a user. The submitter's
+// own vote counts towards accepting it. Users have at most one pending
+// submission per episode and type, and a limited number per hour.
+func (ec *EpisodesController) SubmitMarker(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var marker models.SkipMarker
+
+	if err := c.BodyParser(&marker); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	if role, _ := c.Locals("role").(string); !episodeVisibleTo(role, episode) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Episode not found",
+		})
+	}
+
+	if msg := marker.Validate(episode.Duration); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	submissions := database.DB.Collection("marker_submissions")
+	userID := c.Locals("userID").(string)
+
+	recent, err := submissions.CountDocuments(ctx, bson.M{
+		"userId":    userID,
+		"createdAt": bson.M{"$gte": time.Now().Add(-time.Hour)},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to submit marker",
+			Error:   err.Error(),
+		})
+	}
+	if recent >= maxMarkerSubmissionsPerHour {
+		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Too many submissions, please try again later",
+		})
+	}
+
+	submission := models.MarkerSubmission{
+		EpisodeID: objID,
+		UserID:    userID,
+		Type:      marker.Type,
+		Start:     marker.Start,
+		End:       marker.End,
+		Votes:     []models.MarkerVote{{UserID: userID, Value: 1}},
+		Score:     1,
+		Status:    models.SubmissionStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	// One pending submission per user, episode and marker type
+	filter := bson.M{
+		"episodeId": objID,
+		"userId":    userID,
+		"type":      marker.Type,
+		"status":    models.SubmissionStatusPending,
+	}
+	result, err := submissions.UpdateOne(ctx, filter, bson.M{"$setOnInsert": submission}, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to submit marker",
+			Error:   err.Error(),
+		})
+	}
+	if err != nil || result.UpsertedID == nil {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You already submitted a " + marker.Type + " marker for this episode",
+		})
+	}
+	submission.ID = result.UpsertedID.(primitive.ObjectID)
+
+	if err := ec.acceptMarkerIfConfident(ctx, &submission); err != nil {
+		fmt.Printf("Warning: Failed to accept marker submission %s: %v\n", submission.ID.Hex(), err)
+	}
+
+	return respond(c, fiber.StatusCreated, "Marker submitted successfully", "submission", submission)
+}
+
+// GetMarkerSubmissions lists the pending marker submissions of an episode,
+// highest score first, for users to vote on
+func (ec *EpisodesController) GetMarkerSubmissions(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"episodeId": objID, "status": models.SubmissionStatusPending}
+	opts := options.Find().SetSort(bson.D{{Key: "score", Value: -1}, {Key: "createdAt", Value: 1}})
+	cursor, err := database.DB.Collection("marker_submissions").Find(ctx, filter, opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch marker submissions",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var submissions []models.MarkerSubmission
+	if err = cursor.All(ctx, &submissions); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse marker submissions",
+			Error:   err.Error(),
+		})
+	}
+
+	if submissions == nil {
+		submissions = []models.MarkerSubmission{}
+	}
+
+	return respond(c, fiber.StatusOK, "Marker submissions retrieved successfully", "submissions", submissions)
+}
+
+// VoteMarkerSubmission records a user's vote on a pending marker submission,
+// replacing their earlier vote, and accepts the marker once confident
+func (ec *EpisodesController) VoteMarkerSubmission(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+	submissionID, err := primitive.ObjectIDFromHex(c.Params("submissionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid submission ID",
+		})
+	}
+
+	var req models.MarkerVoteRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	if req.Value != 1 && req.Value != -1 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Vote value must be 1 or -1",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := database.DB.Collection("marker_submissions")
+	filter := bson.M{"_id": submissionID, "episodeId": objID, "status": models.SubmissionStatusPending}
+	userID := c.Locals("userID").(string)
+
+	// Replace the user's earlier vote and recount the score in one update,
+	// so concurrent votes cannot lose each other
+	vote := bson.M{"userId": userID, "value": req.Value}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"votes": bson.M{"$concatArrays": bson.A{
+			bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$votes", bson.A{}}},
+				"cond":  bson.M{"$ne": bson.A{"$$this.userId", userID}},
+			}},
+			bson.A{vote},
+		}}}}},
+		{{Key: "$set", Value: bson.M{"score": bson.M{"$sum": "$votes.value"}}}},
+	}
+
+	var submission models.MarkerSubmission
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&submission)
+	if err == mongo.ErrNoDocuments {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Marker submission not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to record vote",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := ec.acceptMarkerIfConfident(ctx, &submission); err != nil {
+		return episodeErrorResponse(c, err, "Failed to accept marker")
+	}
+
+	return respond(c, fiber.StatusOK, "Vote recorded successfully", "submission", submission)
+}
+
+// acceptMarkerIfConfident adds a submission's marker to its episode once
+// its score reaches the threshold, replacing a community marker of the same
+// type. The update only matches episodes without an editor marker of that
+// type, so a marker set by an editor meanwhile is never replaced.
+func (ec *EpisodesController) acceptMarkerIfConfident(ctx context.Context, submission *models.MarkerSubmission) error {
+	if submission.Score < ec.markerThreshold {
+		return nil
+	}
+
+	filter := bson.M{
+		"_id": submission.EpisodeID,
+		"markers": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"type":   submission.Type,
+			"source": models.MarkerSourceEditor,
+		}}},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"markers": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$markers", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this.type", submission.Type}},
+				}},
+				bson.A{bson.M{"$literal": submission.ToMarker()}},
+			}},
+			"updatedAt": time.Now(),
+		}}},
+	}
+	result, err := database.DB.Collection("episodes").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return nil
+	}
+
+	_, err = database.DB.Collection("marker_submissions").UpdateOne(ctx,
+		bson.M{"_id": submission.ID},
+		bson.M{"$set": bson.M{"status": models.SubmissionStatusAccepted}},
+	)
+	if err != nil {
+		return err
+	}
+	submission.Status = models.SubmissionStatusAccepted
+	return nil
+}
+
+// saveMarkers replaces the skip markers of an episode
+func saveMarkers(ctx context.Context, objID primitive.ObjectID, markers []models.SkipMarker) error {
+	update := bson.M{
+		"$set": bson.M{
+			"markers":   markers,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	result, err := database.DB.Collection("episodes").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	return nil
+}
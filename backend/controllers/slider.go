@@ -60,10 +60,7 @@ func (sc *SliderController) GetAllSliderItems(c *fiber.Ctx) error {
 		items = []SliderItem{}
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    items,
-	})
+	return respond(c, fiber.StatusOK, "", "", items)
 }
 
 // UpdateSliderItems - Update slider items (replace all)
@@ -108,9 +105,5 @@ func (sc *SliderController) UpdateSliderItems(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Slider items updated successfully",
-		"data":    items,
-	})
+	return respond(c, fiber.StatusOK, "Slider items updated successfully", "", items)
 }
@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/config"
+	"toofy-backend/database"
+	"toofy-backend/models"
+)
+
+const (
+	// maxReportsPerHour limits how many sources one user can report per hour
+	maxReportsPerHour = 10
+)
+
+type SourceReportsController struct {
+	// threshold is the number of open reports that disables a source
+	threshold int
+}
+
+func NewSourceReportsController(cfg *config.Config) *SourceReportsController {
+	return &SourceReportsController{threshold: cfg.SourceReportThreshold}
+}
+
+// ReportSource records a user's report that a source of an episode is broken.
+// A source is disabled, and editors are notified, once its open reports reach
+// the threshold.
+func (rc *SourceReportsController) ReportSource(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	var req models.SourceReportRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+	}
+
+	req.Details = strings.TrimSpace(req.Details)
+	if msg := req.Validate(); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: msg,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	episode, err := findEpisode(ctx, objID)
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	role, _ := c.Locals("role").(string)
+	sourceID := c.Params("sourceId")
+	if !episodeVisibleTo(role, episode) || !hasPlayableSource(episode.Sources, sourceID) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Source not found",
+		})
+	}
+
+	reportsCollection := database.DB.Collection("source_reports")
+	userID := c.Locals("userID").(string)
+
+	recent, err := reportsCollection.CountDocuments(ctx, bson.M{
+		"reporterId": userID,
+		"createdAt":  bson.M{"$gte": time.Now().Add(-time.Hour)},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to report source",
+			Error:   err.Error(),
+		})
+	}
+	if recent >= maxReportsPerHour {
+		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Too many reports, please try again later",
+		})
+	}
+
+	report := models.SourceReport{
+		EpisodeID:  objID,
+		SourceID:   sourceID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		ReporterID: userID,
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+
+	// One open report per user and source
+	filter := bson.M{
+		"episodeId":  objID,
+		"sourceId":   sourceID,
+		"reporterId": userID,
+		"status":     models.ReportStatusOpen,
+	}
+	result, err := reportsCollection.UpdateOne(ctx, filter, bson.M{"$setOnInsert": report}, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to report source",
+			Error:   err.Error(),
+		})
+	}
+	if err != nil || result.UpsertedID == nil {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "You already reported this source",
+		})
+	}
+	report.ID = result.UpsertedID.(primitive.ObjectID)
+
+	if err := rc.disableIfReported(ctx, episode, sourceID); err != nil {
+		fmt.Printf("Warning: Failed to check reports of source %s: %v\n", sourceID, err)
+	}
+
+	return respond(c, fiber.StatusCreated, "Source reported successfully", "report", report)
+}
+
+// disableIfReported disables a source once its open reports reach the
+// threshold and notifies editors
+func (rc *SourceReportsController) disableIfReported(ctx context.Context, episode *models.Episode, sourceID string) error {
+	count, err := database.DB.Collection("source_reports").CountDocuments(ctx, bson.M{
+		"episodeId": episode.ID,
+		"sourceId":  sourceID,
+		"status":    models.ReportStatusOpen,
+	})
+	if err != nil || int(count) < rc.threshold {
+		return err
+	}
+
+	// Only the report that crosses the threshold disables the source
+	result, err := database.DB.Collection("episodes").UpdateOne(ctx,
+		bson.M{"_id": episode.ID, "sources": bson.M{"$elemMatch": bson.M{"id": sourceID, "disabled": false}}},
+		bson.M{"$set": bson.M{"sources.$.disabled": true, "updatedAt": time.Now()}},
+	)
+	if err != nil || result.ModifiedCount == 0 {
+		return err
+	}
+
+	normalizeEpisode(episode)
+	editors, err := editorIDs(ctx)
+	if err != nil {
+		return err
+	}
+	return notifyUsers(ctx, editors, models.Notification{
+		Type:         models.NotificationSourceDisabled,
+		AnimeID:      episode.AnimeID,
+		EpisodeID:    episode.ID.Hex(),
+		EpisodeLabel: episode.Label,
+		SourceID:     sourceID,
+		Message:      fmt.Sprintf("Source %s of episode %s was disabled after %d reports", sourceID, episode.Label, count),
+	})
+}
+
+// GetReportQueue lists reported sources with open reports, most reported first
+func (rc *SourceReportsController) GetReportQueue(c *fiber.Ctx) error {
+	pageNum, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || pageNum < 1 {
+		pageNum = 1
+	}
+	limitNum, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limitNum < 1 || limitNum > 100 {
+		limitNum = 50
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": models.ReportStatusOpen}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           bson.M{"episodeId": "$episodeId", "sourceId": "$sourceId"},
+			"reportCount":   bson.M{"$sum": 1},
+			"reasons":       bson.M{"$push": "$reason"},
+			"firstReportAt": bson.M{"$min": "$createdAt"},
+			"lastReportAt":  bson.M{"$max": "$createdAt"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "reportCount", Value: -1}, {Key: "lastReportAt", Value: -1}}}},
+		{{Key: "$skip", Value: (pageNum - 1) * limitNum}},
+		{{Key: "$limit", Value: limitNum}},
+	}
+	cursor, err := database.DB.Collection("source_reports").Aggregate(ctx, pipeline)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch reports",
+			Error:   err.Error(),
+		})
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Key struct {
+			EpisodeID primitive.ObjectID `bson:"episodeId"`
+			SourceID  string             `bson:"sourceId"`
+		} `bson:"_id"`
+		ReportCount   int       `bson:"reportCount"`
+		Reasons       []string  `bson:"reasons"`
+		FirstReportAt time.Time `bson:"firstReportAt"`
+		LastReportAt  time.Time `bson:"lastReportAt"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to parse reports",
+			Error:   err.Error(),
+		})
+	}
+
+	episodeIDs := []primitive.ObjectID{}
+	for _, group := range groups {
+		episodeIDs = append(episodeIDs, group.Key.EpisodeID)
+	}
+	episodes := map[primitive.ObjectID]models.Episode{}
+	if len(episodeIDs) > 0 {
+		opts := options.Find().SetProjection(bson.M{"animeId": 1, "type": 1, "season": 1, "number": 1, "sources": 1})
+		episodeCursor, err := database.DB.Collection("episodes").Find(ctx, bson.M{"_id": bson.M{"$in": episodeIDs}}, opts)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Failed to fetch episodes",
+				Error:   err.Error(),
+			})
+		}
+		var found []models.Episode
+		err = episodeCursor.All(ctx, &found)
+		episodeCursor.Close(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Success: false,
+				Message: "Failed to parse episodes",
+				Error:   err.Error(),
+			})
+		}
+		for _, episode := range found {
+			normalizeEpisode(&episode)
+			episodes[episode.ID] = episode
+		}
+	}
+
+	queue := []models.ReportedSource{}
+	for _, group := range groups {
+		entry := models.ReportedSource{
+			EpisodeID:     group.Key.EpisodeID,
+			SourceID:      group.Key.SourceID,
+			ReportCount:   group.ReportCount,
+			Reasons:       map[string]int{},
+			FirstReportAt: group.FirstReportAt,
+			LastReportAt:  group.LastReportAt,
+		}
+		for _, reason := range group.Reasons {
+			entry.Reasons[reason]++
+		}
+		if episode, ok := episodes[group.Key.EpisodeID]; ok {
+			entry.AnimeID = episode.AnimeID
+			entry.EpisodeLabel = episode.Label
+			for i := range episode.Sources {
+				if episode.Sources[i].ID == group.Key.SourceID {
+					entry.Source = &episode.Sources[i]
+					break
+				}
+			}
+		}
+		queue = append(queue, entry)
+	}
+
+	return respond(c, fiber.StatusOK, "Reports retrieved successfully", "reports", queue)
+}
+
+// ResolveReports closes the open reports of a source once an editor has
+// fixed, replaced or removed it
+func (rc *SourceReportsController) ResolveReports(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("episodeId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := c.Locals("userID").(string)
+	result, err := database.DB.Collection("source_reports").UpdateMany(ctx,
+		bson.M{"episodeId": objID, "sourceId": c.Params("sourceId"), "status": models.ReportStatusOpen},
+		bson.M{"$set": bson.M{
+			"status":     models.ReportStatusResolved,
+			"resolvedBy": userID,
+			"resolvedAt": time.Now(),
+		}},
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to resolve reports",
+			Error:   err.Error(),
+		})
+	}
+
+	if result.ModifiedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "No open reports for this source",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, "Reports resolved successfully", "resolved", result.ModifiedCount)
+}
+
+func hasPlayableSource(sources []models.VideoSource, id string) bool {
+	for _, source := range sources {
+		if source.ID == id && !source.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// editorIDs returns the IDs of the users whose role can edit anime
+func editorIDs(ctx context.Context) ([]string, error) {
+	roles := []string{}
+	for role := range models.DefaultRolePermissions {
+		if models.HasPermission(role, models.PermEditAnime) {
+			roles = append(roles, role)
+		}
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := database.DB.Collection("users").Find(ctx, bson.M{"role": bson.M{"$in": roles}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.ID.Hex())
+	}
+	return ids, nil
+}
@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// maxSubtitleSize limits uploaded subtitle files; a full-length episode is
+// well under 200KB even in ASS
+const maxSubtitleSize = 2 << 20
+
+// subtitleContentTypes are the content types originals are stored with
+var subtitleContentTypes = map[string]string{
+	utils.SubtitleFormatSRT: "application/x-subrip",
+	utils.SubtitleFormatASS: "text/x-ssa",
+	utils.SubtitleFormatSSA: "text/x-ssa",
+	utils.SubtitleFormatVTT: "text/vtt",
+}
+
+type SubtitlesController struct {
+	upload *UploadController
+}
+
+func NewSubtitlesController(upload *UploadController) *SubtitlesController {
+	return &SubtitlesController{upload: upload}
+}
+
+// UploadSubtitle stores an SRT, ASS/SSA or WebVTT file for an episode along
+// with its WebVTT conversion and adds the track to the episode
+func (sc *SubtitlesController) UploadSubtitle(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	language := strings.TrimSpace(c.FormValue("language"))
+	if !models.ValidLanguageTag(language) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "language must be a language code such as ar or en",
+		})
+	}
+
+	label := strings.TrimSpace(c.FormValue("label"))
+	if label == "" {
+		label = models.DefaultSubtitleLabel(language)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "File required",
+		})
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	contentType, ok := subtitleContentTypes[format]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid file type. Must be: srt, ass, ssa, or vtt",
+		})
+	}
+
+	if file.Size > maxSubtitleSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Subtitle file is too large",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to read file",
+			Error:   err.Error(),
+		})
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to read file",
+			Error:   err.Error(),
+		})
+	}
+
+	vtt, err := utils.SubtitleToVTT(data, format)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Failed to convert subtitle file",
+			Error:   err.Error(),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if _, err := findEpisode(ctx, objID); err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	track := models.SubtitleTrack{
+		ID:        uuid.New().String(),
+		Language:  language,
+		Label:     label,
+		Format:    format,
+		IsDefault: c.FormValue("default") == "true",
+		CreatedAt: time.Now(),
+	}
+
+	// The original is kept so a better converter can be rerun later
+	prefix := fmt.Sprintf("%s%s/%s", subtitlePrefix, objID.Hex(), track.ID)
+	track.OriginalURL, err = sc.upload.PutFile(prefix+"."+format, data, contentType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Upload failed",
+			Error:   err.Error(),
+		})
+	}
+	track.URL, err = sc.upload.PutFile(prefix+".vtt", []byte(vtt), "text/vtt; charset=utf-8")
+	if err != nil {
+		sc.upload.DeleteFile(track.OriginalURL)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Upload failed",
+			Error:   err.Error(),
+		})
+	}
+
+	if err := addSubtitle(ctx, objID, &track); err != nil {
+		sc.upload.DeleteFile(track.OriginalURL)
+		sc.upload.DeleteFile(track.URL)
+		return episodeErrorResponse(c, err, "Failed to save subtitle track")
+	}
+
+	return respond(c, fiber.StatusCreated, "Subtitle uploaded successfully", "subtitle", track)
+}
+
+// DeleteSubtitle removes a subtitle track and its files
+func (sc *SubtitlesController) DeleteSubtitle(c *fiber.Ctx) error {
+	objID, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Invalid episode ID",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := findEpisode(ctx, objID); err != nil {
+		return episodeErrorResponse(c, err, "Failed to fetch episode")
+	}
+
+	removed, err := removeSubtitle(ctx, objID, c.Params("trackId"))
+	if err != nil {
+		return episodeErrorResponse(c, err, "Failed to delete subtitle track")
+	}
+
+	// Files are removed after the episode no longer references them
+	if err := sc.upload.DeleteFile(removed.URL); err != nil {
+		fmt.Printf("Warning: Failed to delete subtitle file %s: %v\n", removed.URL, err)
+	}
+	if err := sc.upload.DeleteFile(removed.OriginalURL); err != nil {
+		fmt.Printf("Warning: Failed to delete subtitle file %s: %v\n", removed.OriginalURL, err)
+	}
+
+	return respond(c, fiber.StatusOK, "Subtitle deleted successfully", "", nil)
+}
+
+// addSubtitle appends a track to the subtitles of an episode in a single
+// update, so concurrent uploads do not overwrite each other. A default track
+// takes over from the previous default, and the first track of an episode is
+// always the default; track.IsDefault is updated to match.
+func addSubtitle(ctx context.Context, objID primitive.ObjectID, track *models.SubtitleTrack) error {
+	// $push cannot be combined with a $set on the other tracks' isDefault,
+	// which is the same array, so the new array is built in a pipeline
+	existing := bson.M{"$ifNull": bson.A{"$subtitles", bson.A{}}}
+	others := bson.M{"$map": bson.M{
+		"input": existing,
+		"in": bson.M{"$mergeObjects": bson.A{
+			"$$this",
+			bson.M{"isDefault": bson.M{"$and": bson.A{"$$this.isDefault", !track.IsDefault}}},
+		}},
+	}}
+	added := bson.M{"$mergeObjects": bson.A{
+		bson.M{"$literal": track},
+		bson.M{"isDefault": bson.M{"$or": bson.A{track.IsDefault, bson.M{"$eq": bson.A{bson.M{"$size": existing}, 0}}}}},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"subtitles": bson.M{"$concatArrays": bson.A{others, bson.A{added}}},
+			"updatedAt": time.Now(),
+		}}},
+	}
+
+	var episode models.Episode
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := database.DB.Collection("episodes").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return fiber.NewError(fiber.StatusNotFound, "Episode not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, saved := range episode.Subtitles {
+		if saved.ID == track.ID {
+			track.IsDefault = saved.IsDefault
+		}
+	}
+	return nil
+}
+
+// removeSubtitle removes a track from the subtitles of an episode in a single
+// update and returns it. When it was the default, the first remaining track
+// becomes the default in the same update, so tracks uploaded meanwhile are
+// kept and the episode never ends up without a default.
+func removeSubtitle(ctx context.Context, objID primitive.ObjectID, trackID string) (*models.SubtitleTrack, error) {
+	// The removal works like $pull, which cannot be combined with setting
+	// isDefault on another track of the same array, so it is a pipeline too
+	remaining := bson.M{"$filter": bson.M{
+		"input": "$subtitles",
+		"cond":  bson.M{"$ne": bson.A{"$$this.id", trackID}},
+	}}
+	wasDefault := bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$filter": bson.M{
+			"input": "$subtitles",
+			"cond":  bson.M{"$eq": bson.A{"$$this.id", trackID}},
+		}},
+		"in": "$$this.isDefault",
+	}}}}
+	subtitles := bson.M{"$let": bson.M{
+		"vars": bson.M{"remaining": remaining, "wasDefault": wasDefault},
+		"in": bson.M{"$map": bson.M{
+			"input": bson.M{"$range": bson.A{0, bson.M{"$size": "$$remaining"}}},
+			"as":    "i",
+			"in": bson.M{"$mergeObjects": bson.A{
+				bson.M{"$arrayElemAt": bson.A{"$$remaining", "$$i"}},
+				bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{"$$wasDefault", bson.M{"$eq": bson.A{"$$i", 0}}}},
+					bson.M{"isDefault": true},
+					bson.M{},
+				}},
+			}},
+		}},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"subtitles": subtitles, "updatedAt": time.Now()}}},
+	}
+
+	var episode models.Episode
+	filter := bson.M{"_id": objID, "subtitles.id": trackID}
+	err := database.DB.Collection("episodes").FindOneAndUpdate(ctx, filter, update).Decode(&episode)
+	if err == mongo.ErrNoDocuments {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Subtitle track not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The episode is returned as it was before the update
+	for i := range episode.Subtitles {
+		if episode.Subtitles[i].ID == trackID {
+			return &episode.Subtitles[i], nil
+		}
+	}
+	return nil, fiber.NewError(fiber.StatusNotFound, "Subtitle track not found")
+}
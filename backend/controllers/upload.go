@@ -4,23 +4,37 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"toofy-backend/config"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// Storage prefixes served without a signature, by GetImage and GetFile
+const (
+	coverPrefix    = "covers/"
+	subtitlePrefix = "subtitles/"
 )
 
 type UploadController struct {
-	s3Client *s3.S3
-	bucket   string
-	endpoint string
-	baseURL  string
+	s3Client  *s3.S3
+	bucket    string
+	endpoint  string
+	baseURL   string
+	sizeCache sync.Map // image key -> [2]int{width, height}
 }
 
 func NewUploadController(cfg *config.Config) *UploadController {
@@ -53,13 +67,21 @@ src, _ := file.Open()
 defer src.Close()
 data, _ := io.ReadAll(src)
 
-key := fmt.Sprintf("covers/%s%s", uuid.New().String(), ext)
+key := fmt.Sprintf("%s%s%s", coverPrefix, uuid.New().String(), ext)
+
+// Keep the dimensions with the object so SEO metadata can report them cheaply
+metadata := map[string]*string{}
+if width, height, err := utils.ImageSize(data); err == nil {
+	metadata["Width"] = aws.String(strconv.Itoa(width))
+	metadata["Height"] = aws.String(strconv.Itoa(height))
+}
 
 _, err = uc.s3Client.PutObject(&s3.PutObjectInput{
 Bucket:      aws.String(uc.bucket),
 Key:         aws.String(key),
 Body:        bytes.NewReader(data),
 ContentType: aws.String(file.Header.Get("Content-Type")),
+Metadata:    metadata,
 })
 if err != nil {
 return c.Status(500).JSON(fiber.Map{"success": false, "message": "Upload failed"})
@@ -67,17 +89,22 @@ return c.Status(500).JSON(fiber.Map{"success": false, "message": "Upload failed"
 
 // Build full URL using configured base URL
 url := fmt.Sprintf("%s/api/upload/image/%s", uc.baseURL, key)
-return c.Status(201).JSON(fiber.Map{
-	"success": true,
-	"data": fiber.Map{"url": url, "key": key},
-})
+return respond(c, 201, "", "", fiber.Map{"url": url, "key": key})
 }
 
+// GetImage serves an uploaded cover. Other keys are refused so stored
+// episode media is only reachable through signed /media URLs.
 func (uc *UploadController) GetImage(c *fiber.Ctx) error {
 	key := c.Params("*")
 	if key == "" {
 		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Key required"})
 	}
+	if !publicKey(key, coverPrefix) {
+		return c.Status(404).JSON(fiber.Map{
+			"success": false,
+			"message": "Image not found",
+		})
+	}
 
 	obj, err := uc.s3Client.GetObject(&s3.GetObjectInput{
 		Bucket: aws.String(uc.bucket),
@@ -98,11 +125,7 @@ func (uc *UploadController) GetImage(c *fiber.Ctx) error {
 }
 
 func (uc *UploadController) DeleteCover(c *fiber.Ctx) error {
-	type DeleteRequest struct {
-		URL string `json:"url"`
-	}
-
-	var req DeleteRequest
+	var req models.DeleteCoverRequest
 	if err := c.BodyParser(&req); err != nil {
 		fmt.Printf("[DeleteCover] BodyParser error: %v\n", err)
 		return c.Status(400).JSON(fiber.Map{"success": false, "message": "Invalid request"})
@@ -119,17 +142,7 @@ func (uc *UploadController) DeleteCover(c *fiber.Ctx) error {
 	// Handles both full URLs and relative paths
 	// e.g., "http://localhost:8081/api/upload/image/covers/uuid.jpg" -> "covers/uuid.jpg"
 	// or "/api/upload/image/covers/uuid.jpg" -> "covers/uuid.jpg"
-	key := ""
-	
-	// Find the position of "/api/upload/image/" in the URL
-	apiPath := "/api/upload/image/"
-	if idx := strings.Index(req.URL, apiPath); idx != -1 {
-		// Extract everything after "/api/upload/image/"
-		key = req.URL[idx+len(apiPath):]
-	} else {
-		// If "/api/upload/image/" not found, try direct path
-		key = req.URL
-	}
+	key := imageKeyFromURL(req.URL)
 
 	fmt.Printf("[DeleteCover] Extracted key: %s\n", key)
 
@@ -156,9 +169,195 @@ func (uc *UploadController) DeleteCover(c *fiber.Ctx) error {
 	}
 
 	fmt.Printf("[DeleteCover] Successfully deleted from S3: %s\n", key)
+	if isV2(c) {
+		return respond(c, fiber.StatusOK, "Image deleted successfully", "", fiber.Map{"key": key})
+	}
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Image deleted successfully",
 		"key":     key,
 	})
-}
\ No newline at end of file
+}
+// imageKeyFromURL extracts the storage key from an image URL served by
+// GetImage. Full URLs and relative paths are accepted, and anything else is
+// treated as the key itself.
+// e.g., "http://localhost:8081/api/upload/image/covers/uuid.jpg" -> "covers/uuid.jpg"
+func imageKeyFromURL(url string) string {
+	apiPath := "/api/upload/image/"
+	if idx := strings.Index(url, apiPath); idx != -1 {
+		return url[idx+len(apiPath):]
+	}
+	return url
+}
+
+// ImageSize returns the pixel dimensions of a stored image. Dimensions are
+// read from the object metadata written on upload; older objects fall back
+// to decoding the start of the file. Results are cached per key.
+func (uc *UploadController) ImageSize(url string) (int, int, error) {
+	key := imageKeyFromURL(url)
+	if cached, ok := uc.sizeCache.Load(key); ok {
+		size := cached.([2]int)
+		return size[0], size[1], nil
+	}
+
+	head, err := uc.s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(uc.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	width, height := 0, 0
+	if head.Metadata["Width"] != nil && head.Metadata["Height"] != nil {
+		width, _ = strconv.Atoi(*head.Metadata["Width"])
+		height, _ = strconv.Atoi(*head.Metadata["Height"])
+	}
+
+	if width == 0 || height == 0 {
+		obj, err := uc.s3Client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(uc.bucket),
+			Key:    aws.String(key),
+			Range:  aws.String("bytes=0-65535"),
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		defer obj.Body.Close()
+
+		data, err := io.ReadAll(obj.Body)
+		if err != nil {
+			return 0, 0, err
+		}
+		if width, height, err = utils.ImageSize(data); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	uc.sizeCache.Store(key, [2]int{width, height})
+	return width, height, nil
+}
+
+// PutFile stores a file under key and returns the URL it is served from
+func (uc *UploadController) PutFile(key string, data []byte, contentType string) (string, error) {
+	_, err := uc.s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(uc.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/api/upload/file/%s", uc.baseURL, key), nil
+}
+
+// DeleteFile removes a stored file by the URL returned from PutFile
+func (uc *UploadController) DeleteFile(url string) error {
+	key := fileKeyFromURL(url)
+	if key == "" {
+		return nil
+	}
+	_, err := uc.s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(uc.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// GetFile serves a subtitle file stored with PutFile. Other keys are
+// refused so stored episode media is only reachable through signed /media
+// URLs.
+func (uc *UploadController) GetFile(c *fiber.Ctx) error {
+	key := c.Params("*")
+	if key == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "Key required",
+		})
+	}
+	if !publicKey(key, subtitlePrefix) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "File not found",
+		})
+	}
+
+	return uc.streamObject(c, key, "public, max-age=31536000")
+}
+
+// streamObject streams a stored object to the client without buffering it.
+// Range and conditional requests are forwarded to storage so players can
+// seek and browsers can revalidate.
+func (uc *UploadController) streamObject(c *fiber.Ctx, key string, cacheControl string) error {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(uc.bucket),
+		Key:    aws.String(key),
+	}
+	if rangeHeader := c.Get(fiber.HeaderRange); rangeHeader != "" {
+		input.Range = aws.String(rangeHeader)
+	}
+	if etag := c.Get(fiber.HeaderIfNoneMatch); etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+
+	obj, err := uc.s3Client.GetObject(input)
+	if err != nil {
+		if aerr, ok := err.(awserr.RequestFailure); ok {
+			switch aerr.StatusCode() {
+			case fiber.StatusNotModified:
+				c.Set(fiber.HeaderCacheControl, cacheControl)
+				return c.SendStatus(fiber.StatusNotModified)
+			case fiber.StatusRequestedRangeNotSatisfiable:
+				return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(models.ErrorResponse{
+					Success: false,
+					Message: "Requested range not satisfiable",
+				})
+			}
+		}
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Success: false,
+			Message: "File not found",
+		})
+	}
+
+	if obj.ContentType != nil {
+		c.Set(fiber.HeaderContentType, *obj.ContentType)
+	}
+	if obj.ETag != nil {
+		c.Set(fiber.HeaderETag, *obj.ETag)
+	}
+	if obj.LastModified != nil {
+		c.Set(fiber.HeaderLastModified, obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+
+	if obj.ContentRange != nil {
+		c.Set(fiber.HeaderContentRange, *obj.ContentRange)
+		c.Status(fiber.StatusPartialContent)
+	}
+
+	size := -1
+	if obj.ContentLength != nil {
+		size = int(*obj.ContentLength)
+	}
+	// The body is closed by fasthttp once the response is written
+	return c.SendStream(obj.Body, size)
+}
+
+// publicKey reports whether a storage key lies under a prefix served
+// without a signature. Keys with dot segments are refused as storage
+// clients resolve them, which would escape the prefix.
+func publicKey(key string, prefix string) bool {
+	return strings.HasPrefix(key, prefix) && path.Clean(key) == key
+}
+
+// fileKeyFromURL extracts the storage key from a URL served by GetFile
+func fileKeyFromURL(url string) string {
+	apiPath := "/api/upload/file/"
+	if idx := strings.Index(url, apiPath); idx != -1 {
+		return url[idx+len(apiPath):]
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -47,13 +48,7 @@ func (uc *UsersController) GetAllUsers(c *fiber.Ctx) error {
 		users = []models.User{}
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Users retrieved successfully",
-		"data": fiber.Map{
-			"users": users,
-		},
-	})
+	return respond(c, fiber.StatusOK, "Users retrieved successfully", "users", users)
 }
 
 // GetUserByID returns a user by ID
@@ -81,22 +76,14 @@ func (uc *UsersController) GetUserByID(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "User retrieved successfully",
-		"data": fiber.Map{
-			"user": user,
-		},
-	})
+	return respond(c, fiber.StatusOK, "User retrieved successfully", "user", user)
 }
 
 // UpdateUserRole updates a user's role
 func (uc *UsersController) UpdateUserRole(c *fiber.Ctx) error {
 	userID := c.Params("id")
 
-	var req struct {
-		Role string `json:"role"`
-	}
+	var req models.UpdateRoleRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
@@ -187,10 +174,7 @@ func (uc *UsersController) UpdateUserRole(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "User role updated successfully",
-	})
+	return respond(c, fiber.StatusOK, "User role updated successfully", "", nil)
 }
 
 // DeleteUser deletes a user
@@ -225,20 +209,36 @@ func (uc *UsersController) DeleteUser(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "User deleted successfully",
+	// The personal library, playback progress, follows, notifications,
+	// ratings and reviews belong to the account
+	_, _ = database.DB.Collection("library").DeleteMany(ctx, bson.M{"userId": userID})
+	_, _ = database.DB.Collection("follows").DeleteMany(ctx, bson.M{"userId": userID})
+	_, _ = database.DB.Collection("notifications").DeleteMany(ctx, bson.M{"userId": userID})
+	// Comments are blanked rather than deleted so replies keep their thread
+	_, _ = database.DB.Collection("comments").UpdateMany(ctx, bson.M{"userId": userID}, bson.M{
+		"$set": bson.M{"deleted": true, "body": "", "spoiler": false, "updatedAt": time.Now()},
 	})
+	_, _ = database.DB.Collection("watch_progress").DeleteMany(ctx, bson.M{"userId": userID})
+	_, _ = database.DB.Collection("reviews").DeleteMany(ctx, bson.M{"userId": userID})
+	if rated, err := database.DB.Collection("ratings").Distinct(ctx, "animeId", bson.M{"userId": userID}); err == nil && len(rated) > 0 {
+		_, _ = database.DB.Collection("ratings").DeleteMany(ctx, bson.M{"userId": userID})
+		animeIDs := []string{}
+		for _, id := range rated {
+			if animeID, ok := id.(string); ok {
+				animeIDs = append(animeIDs, animeID)
+			}
+		}
+		if err := recomputeAnimeRatings(ctx, animeIDs...); err != nil {
+			fmt.Printf("Warning: Failed to recompute ratings after deleting user %s: %v\n", userID, err)
+		}
+	}
+
+	return respond(c, fiber.StatusOK, "User deleted successfully", "", nil)
 }
 
 // CreateUser creates a new user (admin only)
 func (uc *UsersController) CreateUser(c *fiber.Ctx) error {
-	var req struct {
-		DisplayName string `json:"displayName"`
-		Email       string `json:"email"`
-		Password    string `json:"password"`
-		Role        string `json:"role"`
-	}
+	var req models.CreateUserRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
@@ -323,11 +323,5 @@ func (uc *UsersController) CreateUser(c *fiber.Ctx) error {
 
 	newUser.ID = result.InsertedID.(primitive.ObjectID)
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "User created successfully",
-		"data": fiber.Map{
-			"user": newUser,
-		},
-	})
+	return respond(c, fiber.StatusCreated, "User created successfully", "user", newUser)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -60,6 +61,213 @@ func createIndexes() {
 	if err != nil {
 		fmt.Printf("Warning: Failed to create anime slug index: %v\n", err)
 	}
+
+	externalIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "externalIds.anilist", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "externalIds.mal", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+	_, err = animeCollection.Indexes().CreateMany(ctx, externalIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create anime external ID indexes: %v\n", err)
+	}
+
+	// Create indexes for episodes collection
+	episodesCollection := DB.Collection("episodes")
+	// Numbers are unique per season and type since specials and later
+	// seasons restart numbering
+	episodeNumberIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "animeId", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "season", Value: 1},
+			{Key: "number", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err = episodesCollection.Indexes().CreateOne(ctx, episodeNumberIndexModel)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create episode number index: %v\n", err)
+	}
+	// The latest episodes list is ordered by release time
+	episodeLatestIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "publicAt", Value: -1}, {Key: "_id", Value: -1}},
+	}
+	_, err = episodesCollection.Indexes().CreateOne(ctx, episodeLatestIndexModel)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create latest episodes index: %v\n", err)
+	}
+	episodeScheduleIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "published", Value: 1}, {Key: "publicAt", Value: 1}},
+	}
+	_, err = episodesCollection.Indexes().CreateOne(ctx, episodeScheduleIndexModel)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create episode schedule index: %v\n", err)
+	}
+
+	// Create indexes for marker submissions collection
+	markerSubmissionsCollection := DB.Collection("marker_submissions")
+	markerSubmissionIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "episodeId", Value: 1}, {Key: "status", Value: 1}, {Key: "score", Value: -1}}},
+		// A user has at most one pending submission per episode and marker type
+		{
+			Keys:    bson.D{{Key: "episodeId", Value: 1}, {Key: "userId", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": "pending"}),
+		},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	_, err = markerSubmissionsCollection.Indexes().CreateMany(ctx, markerSubmissionIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create marker submission indexes: %v\n", err)
+	}
+
+	// Create indexes for source reports collection
+	sourceReportsCollection := DB.Collection("source_reports")
+	sourceReportIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "episodeId", Value: 1}, {Key: "sourceId", Value: 1}}},
+		// A user has at most one open report per source
+		{
+			Keys:    bson.D{{Key: "episodeId", Value: 1}, {Key: "sourceId", Value: 1}, {Key: "reporterId", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": "open"}),
+		},
+		{Keys: bson.D{{Key: "reporterId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	_, err = sourceReportsCollection.Indexes().CreateMany(ctx, sourceReportIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create source report indexes: %v\n", err)
+	}
+
+	// Create indexes for library collection
+	libraryCollection := DB.Collection("library")
+	libraryIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "animeId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "status", Value: 1}, {Key: "updatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "animeId", Value: 1}}},
+	}
+	_, err = libraryCollection.Indexes().CreateMany(ctx, libraryIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create library indexes: %v\n", err)
+	}
+
+	// Create indexes for watch progress collection
+	progressCollection := DB.Collection("watch_progress")
+	progressIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "episodeId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "updatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "animeId", Value: 1}}},
+		{Keys: bson.D{{Key: "episodeId", Value: 1}}},
+	}
+	_, err = progressCollection.Indexes().CreateMany(ctx, progressIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create watch progress indexes: %v\n", err)
+	}
+
+	// Create indexes for ratings collection
+	ratingsCollection := DB.Collection("ratings")
+	ratingIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "animeId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "score", Value: 1}}},
+	}
+	_, err = ratingsCollection.Indexes().CreateMany(ctx, ratingIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create rating indexes: %v\n", err)
+	}
+
+	// Create indexes for reviews collection
+	reviewsCollection := DB.Collection("reviews")
+	reviewIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "animeId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "removed", Value: 1}, {Key: "helpfulCount", Value: -1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "removed", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+	_, err = reviewsCollection.Indexes().CreateMany(ctx, reviewIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create review indexes: %v\n", err)
+	}
+
+	// Index anime by weighted rating for the top rated list
+	_, err = animeCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "rating.weighted", Value: -1}, {Key: "rating.count", Value: -1}},
+	})
+	if err != nil {
+		fmt.Printf("Warning: Failed to create anime rating index: %v\n", err)
+	}
+
+	// Create indexes for comments collection
+	commentsCollection := DB.Collection("comments")
+	commentIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "episodeId", Value: 1}, {Key: "parentId", Value: 1}, {Key: "pinned", Value: -1}, {Key: "reactionCount", Value: -1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "episodeId", Value: 1}, {Key: "parentId", Value: 1}, {Key: "pinned", Value: -1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "parentId", Value: 1}, {Key: "createdAt", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	}
+	_, err = commentsCollection.Indexes().CreateMany(ctx, commentIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create comment indexes: %v\n", err)
+	}
+
+	// Create indexes for follows collection
+	followsCollection := DB.Collection("follows")
+	followIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "animeId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "animeId", Value: 1}}},
+	}
+	_, err = followsCollection.Indexes().CreateMany(ctx, followIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create follow indexes: %v\n", err)
+	}
+
+	// Create indexes for notifications collection
+	notificationsCollection := DB.Collection("notifications")
+	notificationIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "read", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}
+	_, err = notificationsCollection.Indexes().CreateMany(ctx, notificationIndexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create notification indexes: %v\n", err)
+	}
+
+	// Create indexes for anime history collection
+	historyCollection := DB.Collection("anime_history")
+	historyIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "animeId", Value: 1}, {Key: "createdAt", Value: -1}},
+	}
+	_, err = historyCollection.Indexes().CreateOne(ctx, historyIndexModel)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create anime history index: %v\n", err)
+	}
+
+	// Create indexes for change requests collection
+	changeRequestsCollection := DB.Collection("change_requests")
+	changeStatusIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+	}
+	_, err = changeRequestsCollection.Indexes().CreateOne(ctx, changeStatusIndexModel)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create change request status index: %v\n", err)
+	}
 }
 
 func Disconnect() error {
@@ -1,30 +1,68 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 )
 
 type Client struct {
-	ID   string
-	Conn *websocket.Conn
+	ID            string
+	Role          string
+	Authenticated bool // the user ID comes from a verified token
+	Conn          *websocket.Conn
+	rooms         map[string]bool  // pages the client is viewing, guarded by the hub mutex
+	send          chan interface{} // messages waiting for writePump, closed by the hub
+}
+
+const (
+	// maxRoomsPerClient limits how many rooms one connection can join
+	maxRoomsPerClient = 10
+	// sendQueueSize is how many messages may wait for a connection before it
+	// counts as too slow and is disconnected
+	sendQueueSize = 64
+	// writeWait is how long writing one message to a connection may take
+	writeWait = 10 * time.Second
+)
+
+// MessageHandler handles a websocket message sent by an authenticated user
+type MessageHandler func(userID, role string, payload []byte) error
+
+// messageHandlers maps message types to their handler. It is filled by
+// HandleMessage at startup and only read afterwards.
+var messageHandlers = map[string]MessageHandler{}
+
+// HandleMessage registers the handler for websocket messages of a type.
+// Messages of other types are broadcast as before.
+func HandleMessage(messageType string, handler MessageHandler) {
+	messageHandlers[messageType] = handler
 }
 
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan interface{}
+	direct     chan targetedMessage
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
 }
 
+// targetedMessage is a message for some connections only, such as those of
+// some users or those in a room. messageFor returns the message for a
+// connection, or nil if it is not meant for it.
+type targetedMessage struct {
+	messageFor func(client *Client) interface{}
+}
+
 var hub = &Hub{
 	clients:    make(map[*Client]bool),
 	broadcast:  make(chan interface{}, 256),
+	direct:     make(chan targetedMessage, 256),
 	register:   make(chan *Client),
 	unregister: make(chan *Client),
 }
@@ -44,27 +82,153 @@ func (h *Hub) run() {
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				client.Conn.Close()
-			}
+			h.remove(client)
 			h.mu.Unlock()
 			log.Printf("Client %s disconnected. Total clients: %d\n", client.ID, len(h.clients))
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				if err := client.Conn.WriteJSON(message); err != nil {
-					h.unregister <- client
-				}
-			}
-			h.mu.RUnlock()
+			h.deliver(func(client *Client) interface{} { return message })
+
+		case direct := <-h.direct:
+			h.deliver(direct.messageFor)
+		}
+	}
+}
+
+// deliver queues a message for the clients it is meant for. Clients whose
+// queue is full are too slow to keep up and are disconnected, so one of them
+// never holds up the others.
+func (h *Hub) deliver(messageFor func(client *Client) interface{}) {
+	slow := []*Client{}
+	h.mu.RLock()
+	for client := range h.clients {
+		message := messageFor(client)
+		if message == nil {
+			continue
+		}
+		select {
+		case client.send <- message:
+		default:
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(slow) == 0 {
+		return
+	}
+	h.mu.Lock()
+	for _, client := range slow {
+		h.remove(client)
+	}
+	h.mu.Unlock()
+}
+
+// remove drops a client from the hub and closes its send queue, which makes
+// writePump close the connection. The caller holds the hub mutex.
+func (h *Hub) remove(client *Client) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.send)
+	}
+}
+
+// queue hands a message to the hub without blocking the caller, which is
+// usually handling a request. The message is dropped if the hub is behind.
+func (h *Hub) queue(direct targetedMessage) {
+	select {
+	case h.direct <- direct:
+	default:
+		log.Printf("websocket hub is busy, dropping message")
+	}
+}
+
+// writePump writes the queued messages of a client to its connection until
+// the hub closes the queue. After a failed write the connection is closed,
+// which ends the read loop of WebSocketUpgrade and unregisters the client.
+func (c *Client) writePump() {
+	failed := false
+	for message := range c.send {
+		if failed {
+			continue
+		}
+		c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.Conn.WriteJSON(message); err != nil {
+			failed = true
+			c.Conn.Close()
 		}
 	}
+	c.Conn.Close()
 }
 
+// SendToUsers sends a message to every authenticated connection of the
+// given users
+func (h *Hub) SendToUsers(userIDs []string, message interface{}) {
+	ids := map[string]bool{}
+	for _, id := range userIDs {
+		ids[id] = true
+	}
+	h.queue(targetedMessage{
+		messageFor: func(client *Client) interface{} {
+			if !client.Authenticated || !ids[client.ID] {
+				return nil
+			}
+			return message
+		},
+	})
+}
+
+// SendToEachUser sends each user their own message, keyed by user ID, to
+// every authenticated connection of theirs
+func (h *Hub) SendToEachUser(messages map[string]interface{}) {
+	h.queue(targetedMessage{
+		messageFor: func(client *Client) interface{} {
+			if !client.Authenticated {
+				return nil
+			}
+			return messages[client.ID]
+		},
+	})
+}
+
+// SendToRoom sends a message to every connection in a room
+func (h *Hub) SendToRoom(room string, message interface{}) {
+	h.queue(targetedMessage{
+		messageFor: func(client *Client) interface{} {
+			if !client.rooms[room] {
+				return nil
+			}
+			return message
+		},
+	})
+}
+
+// join adds a client to a room, or removes it when join is false. It
+// reports whether the client is in the room afterwards, which it is not once
+// it reached maxRoomsPerClient.
+func (h *Hub) join(client *Client, room string, join bool) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !join {
+		delete(client.rooms, room)
+		return false
+	}
+	if !client.rooms[room] && len(client.rooms) >= maxRoomsPerClient {
+		return false
+	}
+	client.rooms[room] = true
+	return true
+}
+
+// Broadcast sends a message to every connection. Like the targeted sends it
+// never blocks; the message is dropped if the hub is behind.
 func (h *Hub) Broadcast(message interface{}) {
-	h.broadcast <- message
+	select {
+	case h.broadcast <- message:
+	default:
+		log.Printf("websocket hub is busy, dropping broadcast")
+	}
 }
 
 func WebSocketHandler(c *fiber.Ctx) error {
@@ -74,12 +238,17 @@ func WebSocketHandler(c *fiber.Ctx) error {
 	return fiber.ErrUpgradeRequired
 }
 
-func WebSocketUpgrade(c *websocket.Conn, userID string) {
+func WebSocketUpgrade(c *websocket.Conn, userID, role string, authenticated bool) {
 	client := &Client{
-		ID:   userID,
-		Conn: c,
+		ID:            userID,
+		Role:          role,
+		Authenticated: authenticated,
+		Conn:          c,
+		rooms:         map[string]bool{},
+		send:          make(chan interface{}, sendQueueSize),
 	}
 
+	go client.writePump()
 	hub.register <- client
 
 	defer func() {
@@ -87,14 +256,44 @@ func WebSocketUpgrade(c *websocket.Conn, userID string) {
 	}()
 
 	for {
-		var msg map[string]interface{}
-		if err := c.ReadJSON(&msg); err != nil {
+		_, payload, err := c.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("websocket error: %v", err)
 			}
 			return
 		}
 
+		var msg map[string]interface{}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			log.Printf("websocket error: %v", err)
+			return
+		}
+
+		// Clients join the room of the page they view, e.g.
+		// {"type": "subscribe", "room": "anime:<id>"}, to receive its events
+		messageType, _ := msg["type"].(string)
+		if messageType == "subscribe" || messageType == "unsubscribe" {
+			room, _ := msg["room"].(string)
+			if room == "" || len(room) > 64 {
+				continue
+			}
+			if !hub.join(client, room, messageType == "subscribe") && messageType == "subscribe" {
+				log.Printf("websocket client %s cannot join more than %d rooms", userID, maxRoomsPerClient)
+			}
+			continue
+		}
+
+		if messageHandlers[messageType] != nil {
+			if !authenticated {
+				continue
+			}
+			if err := messageHandlers[messageType](userID, role, payload); err != nil {
+				log.Printf("websocket %s message from %s: %v", messageType, userID, err)
+			}
+			continue
+		}
+
 		// Echo message back to all clients
 		hub.Broadcast(map[string]interface{}{
 			"type":    "message",
@@ -113,3 +312,43 @@ func BroadcastUserUpdate(userID string, action string, data interface{}) {
 		"message": fmt.Sprintf("User %s was %s", userID, action),
 	})
 }
+
+// BroadcastToRoom sends an event to the clients in a room, i.e. viewing a
+// page
+func BroadcastToRoom(room string, event string, data interface{}) {
+	hub.SendToRoom(room, map[string]interface{}{
+		"type":  "room",
+		"room":  room,
+		"event": event,
+		"data":  data,
+	})
+}
+
+// NotifyUsers sends a realtime notification to the given users only
+func NotifyUsers(userIDs []string, event string, data interface{}) {
+	if len(userIDs) == 0 {
+		return
+	}
+	hub.SendToUsers(userIDs, map[string]interface{}{
+		"type":  "notification",
+		"event": event,
+		"data":  data,
+	})
+}
+
+// NotifyEachUser sends each user their own realtime notification, keyed by
+// user ID, in one hub message
+func NotifyEachUser(event string, data map[string]interface{}) {
+	if len(data) == 0 {
+		return
+	}
+	messages := make(map[string]interface{}, len(data))
+	for userID, d := range data {
+		messages[userID] = map[string]interface{}{
+			"type":  "notification",
+			"event": event,
+			"data":  d,
+		}
+	}
+	hub.SendToEachUser(messages)
+}
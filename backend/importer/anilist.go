@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// aniListMedia is the subset of an AniList Media object used by the importer
+type aniListMedia struct {
+	ID    int `json:"id"`
+	IDMal int `json:"idMal"`
+	Title struct {
+		Romaji  string `json:"romaji"`
+		English string `json:"english"`
+		Native  string `json:"native"`
+	} `json:"title"`
+	Synonyms    []string `json:"synonyms"`
+	Description string   `json:"description"`
+	Genres      []string `json:"genres"`
+	Format      string   `json:"format"`
+	Status      string   `json:"status"`
+	Season      string   `json:"season"`
+	SeasonYear  int      `json:"seasonYear"`
+	Episodes    int      `json:"episodes"`
+	Studios     struct {
+		Nodes []struct {
+			Name              string `json:"name"`
+			IsAnimationStudio bool   `json:"isAnimationStudio"`
+		} `json:"nodes"`
+	} `json:"studios"`
+}
+
+// parseAniListFile reads an AniList dump. It accepts a bare array of media,
+// an object with a "media" array, or a raw GraphQL Page response.
+func parseAniListFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var media []aniListMedia
+	if err := json.Unmarshal(data, &media); err != nil {
+		var wrapped struct {
+			Media []aniListMedia `json:"media"`
+			Data  struct {
+				Page struct {
+					Media []aniListMedia `json:"media"`
+				} `json:"Page"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid anilist dump: %w", err)
+		}
+		media = wrapped.Media
+		if media == nil {
+			media = wrapped.Data.Page.Media
+		}
+	}
+
+	records := make([]Record, 0, len(media))
+	for _, m := range media {
+		record := Record{
+			AniListID:    m.ID,
+			MALID:        m.IDMal,
+			Description:  cleanDescription(m.Description),
+			Genres:       m.Genres,
+			Status:       mapStatus(m.Status),
+			Type:         mapType(m.Format),
+			Season:       mapSeason(m.Season),
+			SeasonYear:   m.SeasonYear,
+			EpisodeCount: m.Episodes,
+		}
+
+		// Prefer the English title; the others become alternative names
+		for _, title := range []string{m.Title.English, m.Title.Romaji, m.Title.Native} {
+			if title == "" {
+				continue
+			}
+			if record.Title == "" {
+				record.Title = title
+			} else {
+				record.AlternativeNames = append(record.AlternativeNames, title)
+			}
+		}
+		record.AlternativeNames = append(record.AlternativeNames, m.Synonyms...)
+
+		for _, studio := range m.Studios.Nodes {
+			if studio.IsAnimationStudio {
+				record.Studio = studio.Name
+				break
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
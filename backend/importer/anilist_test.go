@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeDump writes a dump file into a temporary directory and returns its path
+func writeDump(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const aniListMediaJSON = `{
+	"id": 154587,
+	"idMal": 52991,
+	"title": {"romaji": "Sousou no Frieren", "english": "Frieren: Beyond Journey's End", "native": "葬送のフリーレン"},
+	"synonyms": ["Frieren at the Funeral"],
+	"description": "The adventure is over<br>but life goes on &amp; on.<i></i>",
+	"genres": ["Adventure", "Drama"],
+	"format": "TV",
+	"status": "FINISHED",
+	"season": "FALL",
+	"seasonYear": 2023,
+	"episodes": 28,
+	"studios": {"nodes": [{"name": "Aniplex", "isAnimationStudio": false}, {"name": "Madhouse", "isAnimationStudio": true}]}
+}`
+
+func TestParseAniListFile(t *testing.T) {
+	want := Record{
+		AniListID:        154587,
+		MALID:            52991,
+		Title:            "Frieren: Beyond Journey's End",
+		AlternativeNames: []string{"Sousou no Frieren", "葬送のフリーレン", "Frieren at the Funeral"},
+		Description:      "The adventure is over\nbut life goes on & on.",
+		Genres:           []string{"Adventure", "Drama"},
+		Studio:           "Madhouse",
+		Status:           "completed",
+		Type:             "TV",
+		Season:           "fall",
+		SeasonYear:       2023,
+		EpisodeCount:     28,
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Record
+		wantErr bool
+	}{
+		{"bare array", `[` + aniListMediaJSON + `]`, []Record{want}, false},
+		{"media object", `{"media": [` + aniListMediaJSON + `]}`, []Record{want}, false},
+		{"graphql page", `{"data": {"Page": {"media": [` + aniListMediaJSON + `]}}}`, []Record{want}, false},
+		{"only romaji title", `[{"id": 1, "title": {"romaji": "Mushishi"}, "format": "TV_SHORT", "status": "RELEASING"}]`,
+			[]Record{{AniListID: 1, Title: "Mushishi", Type: "TV", Status: "ongoing"}}, false},
+		{"empty", `[]`, []Record{}, false},
+		{"invalid", `{"media": 1}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAniListFile(writeDump(t, "anilist.json", tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"toofy-backend/database"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// maxReportedErrors caps the number of per-record errors kept in a summary
+const maxReportedErrors = 100
+
+// Summary reports the outcome of an import run
+type Summary struct {
+	Total     int      `json:"total"`
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   int      `json:"skipped"`
+	Errors    []string `json:"errors"`
+	DryRun    bool     `json:"dryRun"`
+}
+
+func (s *Summary) addError(format string, args ...interface{}) {
+	if len(s.Errors) < maxReportedErrors {
+		s.Errors = append(s.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// catalog is an in-memory index of the existing anime used to match records
+type catalog struct {
+	byAniList map[int]*models.Anime
+	byMAL     map[int]*models.Anime
+	byName    map[string][]*models.Anime
+	slugs     map[string]bool
+}
+
+func loadCatalog(ctx context.Context) (*catalog, error) {
+	cursor, err := database.DB.Collection("anime").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var animes []models.Anime
+	if err = cursor.All(ctx, &animes); err != nil {
+		return nil, err
+	}
+
+	cat := &catalog{
+		byAniList: map[int]*models.Anime{},
+		byMAL:     map[int]*models.Anime{},
+		byName:    map[string][]*models.Anime{},
+		slugs:     map[string]bool{},
+	}
+	for i := range animes {
+		cat.add(&animes[i])
+	}
+	return cat, nil
+}
+
+func (cat *catalog) add(anime *models.Anime) {
+	if anime.ExternalIDs != nil {
+		if anime.ExternalIDs.AniList != 0 {
+			cat.byAniList[anime.ExternalIDs.AniList] = anime
+		}
+		if anime.ExternalIDs.MAL != 0 {
+			cat.byMAL[anime.ExternalIDs.MAL] = anime
+		}
+	}
+	for _, name := range append([]string{anime.Title}, anime.AlternativeNames...) {
+		if normalized := utils.NormalizeTitle(name); normalized != "" {
+			cat.byName[normalized] = append(cat.byName[normalized], anime)
+		}
+	}
+	if anime.Slug != "" {
+		cat.slugs[anime.Slug] = true
+	}
+}
+
+// match finds the existing anime a record describes: by external ID first,
+// then by an exact normalized name with a compatible type and year
+func (cat *catalog) match(rec *Record) *models.Anime {
+	if anime, ok := cat.byAniList[rec.AniListID]; ok && rec.AniListID != 0 {
+		return anime
+	}
+	if anime, ok := cat.byMAL[rec.MALID]; ok && rec.MALID != 0 {
+		return anime
+	}
+
+	for _, name := range append([]string{rec.Title}, rec.AlternativeNames...) {
+		for _, anime := range cat.byName[utils.NormalizeTitle(name)] {
+			if rec.Type != "" && anime.Type != "" && rec.Type != anime.Type {
+				continue
+			}
+			if rec.SeasonYear != 0 && anime.SeasonYear != 0 && rec.SeasonYear != anime.SeasonYear {
+				continue
+			}
+			// Never match an entry already linked to a different external record
+			if anime.ExternalIDs != nil {
+				if rec.AniListID != 0 && anime.ExternalIDs.AniList != 0 && anime.ExternalIDs.AniList != rec.AniListID {
+					continue
+				}
+				if rec.MALID != 0 && anime.ExternalIDs.MAL != 0 && anime.ExternalIDs.MAL != rec.MALID {
+					continue
+				}
+			}
+			return anime
+		}
+	}
+	return nil
+}
+
+// uniqueSlug returns a slug for the title that is not used by any anime
+func (cat *catalog) uniqueSlug(rec *Record) string {
+	base := utils.Slugify(rec.Title)
+	if base == "" {
+		base = fmt.Sprintf("anime-%d-%d", rec.AniListID, rec.MALID)
+	}
+	slug := base
+	for i := 2; cat.slugs[slug]; i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
+
+// Import creates or enriches anime from the given records. Existing values
+// are only replaced when they are empty or were set by an earlier import;
+// anything an editor entered or changed is left untouched. Alternative names
+// and genres are only ever added to.
+func Import(ctx context.Context, records []Record, dryRun bool) (*Summary, error) {
+	cat, err := loadCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{Total: len(records), Errors: []string{}, DryRun: dryRun}
+	animeCollection := database.DB.Collection("anime")
+
+	for i := range records {
+		rec := &records[i]
+		rec.Title = strings.TrimSpace(rec.Title)
+		if rec.Title == "" {
+			summary.Skipped++
+			summary.addError("record %d: missing title", i)
+			continue
+		}
+
+		existing := cat.match(rec)
+		if existing == nil {
+			if rec.Type == "" || rec.Status == "" {
+				summary.Skipped++
+				summary.addError("%q: cannot create without a known type and status", rec.Title)
+				continue
+			}
+
+			anime := newAnime(rec)
+			anime.Slug = cat.uniqueSlug(rec)
+			if !dryRun {
+				result, err := animeCollection.InsertOne(ctx, anime)
+				if err != nil {
+					summary.addError("%q: %v", rec.Title, err)
+					summary.Skipped++
+					continue
+				}
+				anime.ID = result.InsertedID.(primitive.ObjectID)
+			}
+			cat.add(anime)
+			summary.Created++
+			continue
+		}
+
+		set, imported := enrich(existing, rec)
+		if len(set) == 0 {
+			summary.Unchanged++
+			continue
+		}
+
+		if !dryRun {
+			set["updatedAt"] = time.Now()
+			update := bson.M{"$set": set}
+			if len(imported) > 0 {
+				update["$addToSet"] = bson.M{"importedFields": bson.M{"$each": imported}}
+			}
+			if _, err := animeCollection.UpdateOne(ctx, bson.M{"_id": existing.ID}, update); err != nil {
+				summary.addError("%q: %v", rec.Title, err)
+				summary.Skipped++
+				continue
+			}
+		}
+		cat.add(existing)
+		summary.Updated++
+	}
+
+	return summary, nil
+}
+
+// newAnime builds an anime from a record, marking every field it sets as imported
+func newAnime(rec *Record) *models.Anime {
+	anime := &models.Anime{
+		Title:            rec.Title,
+		AlternativeNames: mergeNames(rec.Title, nil, rec.AlternativeNames),
+		Description:      rec.Description,
+		Genres:           mergeGenres(nil, rec.Genres),
+		Status:           rec.Status,
+		Type:             rec.Type,
+		EpisodeCount:     rec.EpisodeCount,
+		Studio:           rec.Studio,
+		Season:           rec.Season,
+		SeasonYear:       rec.SeasonYear,
+		ExternalIDs:      &models.ExternalIDs{AniList: rec.AniListID, MAL: rec.MALID},
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	for _, field := range scalarFields(anime, rec) {
+		if !reflect.ValueOf(field.incoming).IsZero() {
+			anime.ImportedFields = append(anime.ImportedFields, field.name)
+		}
+	}
+	return anime
+}
+
+// scalarField pairs the current and incoming value of a single-valued field
+type scalarField struct {
+	name     string
+	current  interface{}
+	incoming interface{}
+}
+
+func scalarFields(anime *models.Anime, rec *Record) []scalarField {
+	return []scalarField{
+		{"title", anime.Title, rec.Title},
+		{"description", anime.Description, rec.Description},
+		{"status", anime.Status, rec.Status},
+		{"type", anime.Type, rec.Type},
+		{"episodeCount", anime.EpisodeCount, rec.EpisodeCount},
+		{"studio", anime.Studio, rec.Studio},
+		{"season", anime.Season, rec.Season},
+		{"seasonYear", anime.SeasonYear, rec.SeasonYear},
+	}
+}
+
+// enrich applies a record to an existing anime following the field-level
+// merge rules. It returns the fields to set and the scalar fields that now
+// hold imported values; the anime is updated in place.
+func enrich(anime *models.Anime, rec *Record) (bson.M, []string) {
+	set := bson.M{}
+	imported := []string{}
+
+	importedBefore := map[string]bool{}
+	for _, field := range anime.ImportedFields {
+		importedBefore[field] = true
+	}
+
+	for _, field := range scalarFields(anime, rec) {
+		if reflect.ValueOf(field.incoming).IsZero() || field.current == field.incoming {
+			continue
+		}
+		// A non-empty value not set by an import is editor-curated
+		if !reflect.ValueOf(field.current).IsZero() && !importedBefore[field.name] {
+			continue
+		}
+		set[field.name] = field.incoming
+		imported = append(imported, field.name)
+	}
+
+	for _, field := range imported {
+		switch field {
+		case "title":
+			anime.Title = rec.Title
+		case "description":
+			anime.Description = rec.Description
+		case "status":
+			anime.Status = rec.Status
+		case "type":
+			anime.Type = rec.Type
+		case "episodeCount":
+			anime.EpisodeCount = rec.EpisodeCount
+		case "studio":
+			anime.Studio = rec.Studio
+		case "season":
+			anime.Season = rec.Season
+		case "seasonYear":
+			anime.SeasonYear = rec.SeasonYear
+		}
+		if !importedBefore[field] {
+			anime.ImportedFields = append(anime.ImportedFields, field)
+		}
+	}
+
+	// The record's own title becomes an alternative name when it differs
+	names := mergeNames(anime.Title, anime.AlternativeNames, append([]string{rec.Title}, rec.AlternativeNames...))
+	if len(names) > len(anime.AlternativeNames) {
+		anime.AlternativeNames = names
+		set["alternativeNames"] = names
+	}
+
+	genres := mergeGenres(anime.Genres, rec.Genres)
+	if len(genres) > len(anime.Genres) {
+		anime.Genres = genres
+		set["genres"] = genres
+	}
+
+	if anime.ExternalIDs == nil {
+		anime.ExternalIDs = &models.ExternalIDs{}
+	}
+	if anime.ExternalIDs.AniList == 0 && rec.AniListID != 0 {
+		anime.ExternalIDs.AniList = rec.AniListID
+		set["externalIds.anilist"] = rec.AniListID
+	}
+	if anime.ExternalIDs.MAL == 0 && rec.MALID != 0 {
+		anime.ExternalIDs.MAL = rec.MALID
+		set["externalIds.mal"] = rec.MALID
+	}
+
+	return set, imported
+}
+
+// mergeNames appends new names to the existing ones, skipping the title and
+// names that normalize to one already present
+func mergeNames(title string, existing, incoming []string) []string {
+	seen := map[string]bool{utils.NormalizeTitle(title): true}
+	names := []string{}
+	for _, name := range existing {
+		seen[utils.NormalizeTitle(name)] = true
+		names = append(names, name)
+	}
+	for _, name := range incoming {
+		normalized := utils.NormalizeTitle(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// mergeGenres appends new genres to the existing ones, ignoring case
+func mergeGenres(existing, incoming []string) []string {
+	seen := map[string]bool{}
+	genres := []string{}
+	for _, genre := range existing {
+		seen[strings.ToLower(genre)] = true
+		genres = append(genres, genre)
+	}
+	for _, genre := range incoming {
+		key := strings.ToLower(strings.TrimSpace(genre))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		genres = append(genres, strings.TrimSpace(genre))
+	}
+	return genres
+}
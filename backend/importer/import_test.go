@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"toofy-backend/models"
+)
+
+func TestEnrich(t *testing.T) {
+	tests := []struct {
+		name         string
+		anime        models.Anime
+		rec          Record
+		wantSet      bson.M
+		wantImported []string
+	}{
+		{
+			name:         "editor-curated fields are kept",
+			anime:        models.Anime{Title: "Frieren", Description: "Written by an editor", Status: "ongoing", Type: "TV", EpisodeCount: 28},
+			rec:          Record{Title: "Frieren", Description: "From the dump", Status: "completed", Type: "TV", EpisodeCount: 28},
+			wantSet:      bson.M{},
+			wantImported: []string{},
+		},
+		{
+			name:         "empty fields are filled",
+			anime:        models.Anime{Title: "Frieren", Type: "TV"},
+			rec:          Record{Title: "Frieren", Studio: "Madhouse", Season: "fall", SeasonYear: 2023},
+			wantSet:      bson.M{"studio": "Madhouse", "season": "fall", "seasonYear": 2023},
+			wantImported: []string{"studio", "season", "seasonYear"},
+		},
+		{
+			name:         "imported fields are refreshed",
+			anime:        models.Anime{Title: "Frieren", Status: "ongoing", EpisodeCount: 12, ImportedFields: []string{"status"}},
+			rec:          Record{Title: "Frieren", Status: "completed", EpisodeCount: 28},
+			wantSet:      bson.M{"status": "completed"},
+			wantImported: []string{"status"},
+		},
+		{
+			name:         "empty record values change nothing",
+			anime:        models.Anime{Title: "Frieren", Studio: "Madhouse", ImportedFields: []string{"studio"}},
+			rec:          Record{Title: "Frieren"},
+			wantSet:      bson.M{},
+			wantImported: []string{},
+		},
+		{
+			name:  "names and genres are only added to",
+			anime: models.Anime{Title: "Frieren", AlternativeNames: []string{"Sousou no Frieren"}, Genres: []string{"Drama"}},
+			rec:   Record{Title: "Frieren: Beyond Journey's End", AlternativeNames: []string{"sousou no frieren"}, Genres: []string{"drama", "Adventure"}},
+			wantSet: bson.M{
+				"alternativeNames": []string{"Sousou no Frieren", "Frieren: Beyond Journey's End"},
+				"genres":           []string{"Drama", "Adventure"},
+			},
+			wantImported: []string{},
+		},
+		{
+			name:         "missing external IDs are added",
+			anime:        models.Anime{Title: "Frieren", ExternalIDs: &models.ExternalIDs{AniList: 154587}},
+			rec:          Record{Title: "Frieren", AniListID: 1, MALID: 52991},
+			wantSet:      bson.M{"externalIds.mal": 52991},
+			wantImported: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anime := tt.anime
+			set, imported := enrich(&anime, &tt.rec)
+			if !reflect.DeepEqual(set, tt.wantSet) {
+				t.Errorf("set = %v, want %v", set, tt.wantSet)
+			}
+			if !reflect.DeepEqual(imported, tt.wantImported) {
+				t.Errorf("imported = %v, want %v", imported, tt.wantImported)
+			}
+		})
+	}
+}
+
+func TestNewAnimeMarksImportedFields(t *testing.T) {
+	anime := newAnime(&Record{Title: "Frieren", Status: "completed", Type: "TV", EpisodeCount: 28})
+
+	want := []string{"title", "status", "type", "episodeCount"}
+	if !reflect.DeepEqual(anime.ImportedFields, want) {
+		t.Errorf("ImportedFields = %v, want %v", anime.ImportedFields, want)
+	}
+}
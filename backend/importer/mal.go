@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// malAnime is the subset of a Jikan (MyAnimeList) anime object used by the importer
+type malAnime struct {
+	MalID         int      `json:"mal_id"`
+	Title         string   `json:"title"`
+	TitleEnglish  string   `json:"title_english"`
+	TitleJapanese string   `json:"title_japanese"`
+	TitleSynonyms []string `json:"title_synonyms"`
+	Type          string   `json:"type"`
+	Episodes      int      `json:"episodes"`
+	Status        string   `json:"status"`
+	Season        string   `json:"season"`
+	Year          int      `json:"year"`
+	Synopsis      string   `json:"synopsis"`
+	Genres        []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Studios []struct {
+		Name string `json:"name"`
+	} `json:"studios"`
+}
+
+// parseMALJSONFile reads a MAL dump in Jikan format, either a bare array or
+// an object with a "data" array
+func parseMALJSONFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []malAnime
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			Data []malAnime `json:"data"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid mal dump: %w", err)
+		}
+		entries = wrapped.Data
+	}
+
+	records := make([]Record, 0, len(entries))
+	for _, entry := range entries {
+		record := Record{
+			MALID:        entry.MalID,
+			Description:  entry.Synopsis,
+			Status:       mapStatus(entry.Status),
+			Type:         mapType(entry.Type),
+			Season:       mapSeason(entry.Season),
+			SeasonYear:   entry.Year,
+			EpisodeCount: entry.Episodes,
+		}
+
+		for _, title := range []string{entry.TitleEnglish, entry.Title, entry.TitleJapanese} {
+			if title == "" {
+				continue
+			}
+			if record.Title == "" {
+				record.Title = title
+			} else {
+				record.AlternativeNames = append(record.AlternativeNames, title)
+			}
+		}
+		record.AlternativeNames = append(record.AlternativeNames, entry.TitleSynonyms...)
+
+		for _, genre := range entry.Genres {
+			record.Genres = append(record.Genres, genre.Name)
+		}
+		if len(entry.Studios) > 0 {
+			record.Studio = entry.Studios[0].Name
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// malExport is the XML list export produced by MyAnimeList
+type malExport struct {
+	Anime []struct {
+		ID       int    `xml:"series_animedb_id"`
+		Title    string `xml:"series_title"`
+		Type     string `xml:"series_type"`
+		Episodes int    `xml:"series_episodes"`
+	} `xml:"anime"`
+}
+
+// parseMALXMLFile reads a MyAnimeList XML export. It only carries titles,
+// types and episode counts; the status it lists is the user's watch status,
+// not the airing status. Its records therefore have no status and can only
+// enrich anime that already exist, never create new ones.
+func parseMALXMLFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var export malExport
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid mal export: %w", err)
+	}
+
+	records := make([]Record, 0, len(export.Anime))
+	for _, entry := range export.Anime {
+		records = append(records, Record{
+			MALID:        entry.ID,
+			Title:        entry.Title,
+			Type:         mapType(entry.Type),
+			EpisodeCount: entry.Episodes,
+		})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+const malAnimeJSON = `{
+	"mal_id": 52991,
+	"title": "Sousou no Frieren",
+	"title_english": "Frieren: Beyond Journey's End",
+	"title_japanese": "葬送のフリーレン",
+	"title_synonyms": ["Frieren at the Funeral"],
+	"type": "TV",
+	"episodes": 28,
+	"status": "Finished Airing",
+	"season": "fall",
+	"year": 2023,
+	"synopsis": "The adventure is over.",
+	"genres": [{"name": "Adventure"}, {"name": "Drama"}],
+	"studios": [{"name": "Madhouse"}]
+}`
+
+func TestParseMALJSONFile(t *testing.T) {
+	want := Record{
+		MALID:            52991,
+		Title:            "Frieren: Beyond Journey's End",
+		AlternativeNames: []string{"Sousou no Frieren", "葬送のフリーレン", "Frieren at the Funeral"},
+		Description:      "The adventure is over.",
+		Genres:           []string{"Adventure", "Drama"},
+		Studio:           "Madhouse",
+		Status:           "completed",
+		Type:             "TV",
+		Season:           "fall",
+		SeasonYear:       2023,
+		EpisodeCount:     28,
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Record
+		wantErr bool
+	}{
+		{"bare array", `[` + malAnimeJSON + `]`, []Record{want}, false},
+		{"data object", `{"data": [` + malAnimeJSON + `]}`, []Record{want}, false},
+		{"not yet aired special", `[{"mal_id": 2, "title": "Frieren Mini", "type": "TV Special", "status": "Not yet aired"}]`,
+			[]Record{{MALID: 2, Title: "Frieren Mini", Type: "Special", Status: "upcoming"}}, false},
+		{"invalid", `{"data": "none"}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMALJSONFile(writeDump(t, "mal.json", tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMALXMLFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Record
+		wantErr bool
+	}{
+		{
+			name: "list export",
+			content: `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo><user_name>someone</user_name></myinfo>
+	<anime>
+		<series_animedb_id>52991</series_animedb_id>
+		<series_title><![CDATA[Sousou no Frieren]]></series_title>
+		<series_type>TV</series_type>
+		<series_episodes>28</series_episodes>
+		<my_status>Completed</my_status>
+	</anime>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title>Cowboy Bebop: Tengoku no Tobira</series_title>
+		<series_type>Movie</series_type>
+		<series_episodes>1</series_episodes>
+		<my_status>Watching</my_status>
+	</anime>
+</myanimelist>`,
+			// The watch status is the user's, so no airing status is derived
+			want: []Record{
+				{MALID: 52991, Title: "Sousou no Frieren", Type: "TV", EpisodeCount: 28},
+				{MALID: 1, Title: "Cowboy Bebop: Tengoku no Tobira", Type: "Movie", EpisodeCount: 1},
+			},
+		},
+		{name: "empty export", content: `<myanimelist></myanimelist>`, want: []Record{}},
+		{name: "invalid", content: `<myanimelist>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMALXMLFile(writeDump(t, "mal.xml", tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported dump sources
+const (
+	SourceAniList = "anilist"
+	SourceMAL     = "mal"
+)
+
+// Record is one anime entry read from a metadata dump, already mapped to our
+// field values
+type Record struct {
+	AniListID        int
+	MALID            int
+	Title            string
+	AlternativeNames []string
+	Description      string
+	Genres           []string
+	Studio           string
+	Status           string // ongoing, completed, upcoming
+	Type             string // TV, Movie, OVA, ONA, Special
+	Season           string // spring, summer, fall, winter
+	SeasonYear       int
+	EpisodeCount     int
+}
+
+// ParseFile reads a dump file from disk. AniList dumps are JSON; MAL dumps
+// may be JSON (Jikan format) or XML (MAL list export, which only enriches
+// existing anime).
+func ParseFile(path, source string) ([]Record, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch source {
+	case SourceAniList:
+		if ext != ".json" {
+			return nil, fmt.Errorf("anilist dumps must be .json files")
+		}
+		return parseAniListFile(path)
+	case SourceMAL:
+		switch ext {
+		case ".json":
+			return parseMALJSONFile(path)
+		case ".xml":
+			return parseMALXMLFile(path)
+		}
+		return nil, fmt.Errorf("mal dumps must be .json or .xml files")
+	}
+
+	return nil, fmt.Errorf("unknown source %q (expected %q or %q)", source, SourceAniList, SourceMAL)
+}
+
+// mapType converts an AniList format or MAL type to our anime type
+func mapType(value string) string {
+	switch strings.ToUpper(strings.ReplaceAll(value, " ", "_")) {
+	case "TV", "TV_SHORT":
+		return "TV"
+	case "MOVIE":
+		return "Movie"
+	case "OVA":
+		return "OVA"
+	case "ONA":
+		return "ONA"
+	case "SPECIAL", "TV_SPECIAL", "MUSIC":
+		return "Special"
+	}
+	return ""
+}
+
+// mapStatus converts an AniList or MAL airing status to our anime status
+func mapStatus(value string) string {
+	switch strings.ToUpper(strings.ReplaceAll(value, " ", "_")) {
+	case "FINISHED", "FINISHED_AIRING", "CANCELLED":
+		return "completed"
+	case "RELEASING", "CURRENTLY_AIRING", "HIATUS":
+		return "ongoing"
+	case "NOT_YET_RELEASED", "NOT_YET_AIRED":
+		return "upcoming"
+	}
+	return ""
+}
+
+// mapSeason converts an AniList or MAL season to our season value
+func mapSeason(value string) string {
+	season := strings.ToLower(value)
+	switch season {
+	case "spring", "summer", "fall", "winter":
+		return season
+	}
+	return ""
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// cleanDescription strips the HTML markup AniList descriptions contain
+func cleanDescription(value string) string {
+	value = strings.ReplaceAll(value, "<br>", "\n")
+	value = htmlTagPattern.ReplaceAllString(value, "")
+	return strings.TrimSpace(html.UnescapeString(value))
+}
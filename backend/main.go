@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"toofy-backend/config"
+	"toofy-backend/controllers"
 	"toofy-backend/database"
 	"toofy-backend/routes"
 )
@@ -22,6 +23,12 @@ func main() {
 	}
 	defer database.Disconnect()
 
+	// Release scheduled episodes when their public time passes
+	controllers.StartEpisodePublisher(cfg)
+
+	// Keep the weighted anime ratings in step with the site mean score
+	controllers.StartRatingRanker(cfg)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName: "Toofy Backend",
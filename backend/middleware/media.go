@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/config"
+	"toofy-backend/models"
+	"toofy-backend/utils"
+)
+
+// SignedMedia only lets requests with a valid, unexpired media signature
+// through. The signed user is stored in the userID local and the verified
+// *utils.MediaGrant in mediaGrant.
+func SignedMedia(cfg *config.Config) fiber.Handler {
+	signer := utils.NewMediaSigner(cfg.MediaSigningKey, cfg.MediaURLTTL, cfg.MediaBindIP)
+
+	return func(c *fiber.Ctx) error {
+		query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+		if err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Success: false,
+				Message: utils.ErrMediaURLInvalid.Error(),
+			})
+		}
+
+		grant, err := signer.Verify(c.Path(), query, c.IP())
+		if err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+
+		c.Locals("userID", grant.UserID)
+		c.Locals("mediaGrant", grant)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/config"
+)
+
+// v1DeprecatedAt is when /api/v2 shipped and the unversioned API was deprecated
+var v1DeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// DeprecatedV1 marks responses of the unversioned /api routes as deprecated
+// (RFC 9745), announces the sunset date (RFC 8594) and links the v2 route
+func DeprecatedV1(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", fmt.Sprintf("@%d", v1DeprecatedAt.Unix()))
+		if !cfg.APIV1Sunset.IsZero() {
+			c.Set("Sunset", cfg.APIV1Sunset.UTC().Format(http.TimeFormat))
+		}
+		successor := "/api/v2" + strings.TrimPrefix(c.Path(), "/api")
+		c.Set(fiber.HeaderLink, fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+		return c.Next()
+	}
+}
+
+// V2Envelope marks requests to /api/v2 so the handlers shared with /api
+// respond with models.Envelope: the resource directly in "data" and
+// pagination in "meta". Error bodies already have the envelope's fields.
+func V2Envelope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("apiVersion", "v2")
+		return c.Next()
+	}
+}
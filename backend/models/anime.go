@@ -14,16 +14,130 @@ type Anime struct {
 	Description       string             `json:"description" bson:"description"`
 	CoverUrl          string             `json:"coverUrl" bson:"coverUrl"`
 	Genres            []string           `json:"genres" bson:"genres"`
-	Status            string             `json:"status" bson:"status"` // ongoing, completed, upcoming
-	Type              string             `json:"type" bson:"type"`     // TV, Movie, OVA, ONA, Special
-	EpisodeCount      int                `json:"episodeCount" bson:"episodeCount"`
+	Status            string             `json:"status" bson:"status"`                       // ongoing, completed, upcoming
+	AutoStatus        bool               `json:"autoStatus" bson:"autoStatus,omitempty"`     // status last set from the published episodes
+	Type              string             `json:"type" bson:"type"`                           // TV, Movie, OVA, ONA, Special
+	EpisodeCount      int                `json:"episodeCount" bson:"episodeCount"`           // expected total, 0 if unknown
+	PublishedEpisodes int                `json:"publishedEpisodes" bson:"publishedEpisodes"` // kept in sync with the episodes collection
+	LatestEpisode     float64            `json:"latestEpisode" bson:"latestEpisode"`
+	LastEpisodeAt     *time.Time         `json:"lastEpisodeAt,omitempty" bson:"lastEpisodeAt,omitempty"`
+	Rating            AnimeRating        `json:"rating" bson:"rating"` // kept in sync with the ratings collection
 	Studio            string             `json:"studio" bson:"studio"`
 	Season            string             `json:"season" bson:"season"` // spring, summer, fall, winter
 	SeasonYear        int                `json:"seasonYear" bson:"seasonYear"`
+	ExternalIDs       *ExternalIDs       `json:"externalIds,omitempty" bson:"externalIds,omitempty"`
+	ImportedFields    []string           `json:"importedFields,omitempty" bson:"importedFields,omitempty"` // fields last set by a metadata import
 	CreatedAt         time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt         time.Time          `json:"updatedAt" bson:"updatedAt"`
 }
 
+// AnimeRef is the part of an anime shown next to content that belongs to it
+type AnimeRef struct {
+	ID       primitive.ObjectID `json:"id"`
+	Title    string             `json:"title"`
+	Slug     string             `json:"slug"`
+	CoverUrl string             `json:"coverUrl"`
+}
+
+// Ref returns the reference shown next to content that belongs to the anime
+func (a *Anime) Ref() AnimeRef {
+	return AnimeRef{ID: a.ID, Title: a.Title, Slug: a.Slug, CoverUrl: a.CoverUrl}
+}
+
+// AnimeRating aggregates the user ratings of an anime
+type AnimeRating struct {
+	Mean         float64        `json:"mean" bson:"mean"`
+	Count        int            `json:"count" bson:"count"`
+	Sum          int            `json:"-" bson:"sum"`
+	Distribution map[string]int `json:"distribution" bson:"distribution,omitempty"` // score "1"-"10" -> votes, scores without votes may be missing
+	Weighted     float64        `json:"weighted" bson:"weighted"`                   // Bayesian rating used for ranking, 0 without votes
+}
+
+// ExternalIDs links an anime to its entries on external databases
+type ExternalIDs struct {
+	AniList int `json:"anilist,omitempty" bson:"anilist,omitempty"`
+	MAL     int `json:"mal,omitempty" bson:"mal,omitempty"`
+}
+
+// AnimeRequest is the request body for creating or updating an anime
+type AnimeRequest struct {
+	Title            string   `json:"title"`
+	Slug             string   `json:"slug"`
+	AlternativeNames []string `json:"alternativeNames"`
+	Description      string   `json:"description"`
+	CoverUrl         string   `json:"coverUrl"`
+	Genres           []string `json:"genres"`
+	Status           string   `json:"status"`
+	Type             string   `json:"type"`
+	EpisodeCount     int      `json:"episodeCount"`
+	Studio           string   `json:"studio"`
+	Season           string   `json:"season"`
+	SeasonYear       int      `json:"seasonYear"`
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *AnimeRequest) Validate() string {
+	// Validate required fields
+	if r.Title == "" {
+		return "Title is required"
+	}
+
+	// Validate season if provided
+	if r.Season != "" {
+		validSeasons := map[string]bool{"spring": true, "summer": true, "fall": true, "winter": true}
+		if !validSeasons[r.Season] {
+			return "Invalid season. Must be: spring, summer, fall, or winter"
+		}
+	}
+
+	// Validate status
+	validStatuses := map[string]bool{"ongoing": true, "completed": true, "upcoming": true}
+	if !validStatuses[r.Status] {
+		return "Invalid status. Must be: ongoing, completed, or upcoming"
+	}
+
+	// Validate type
+	validTypes := map[string]bool{"TV": true, "Movie": true, "OVA": true, "ONA": true, "Special": true}
+	if !validTypes[r.Type] {
+		return "Invalid type. Must be: TV, Movie, OVA, ONA, or Special"
+	}
+
+	return ""
+}
+
+// ToAnime builds an anime document from the request
+func (r *AnimeRequest) ToAnime() Anime {
+	return Anime{
+		Title:            r.Title,
+		Slug:             r.Slug,
+		AlternativeNames: r.AlternativeNames,
+		Description:      r.Description,
+		CoverUrl:         r.CoverUrl,
+		Genres:           r.Genres,
+		Status:           r.Status,
+		Type:             r.Type,
+		EpisodeCount:     r.EpisodeCount,
+		Studio:           r.Studio,
+		Season:           r.Season,
+		SeasonYear:       r.SeasonYear,
+	}
+}
+
+// MergeAnimeRequest is the request body for merging duplicate anime
+type MergeAnimeRequest struct {
+	SurvivorID   string            `json:"survivorId"`
+	LoserIDs     []string          `json:"loserIds"`
+	FieldSources map[string]string `json:"fieldSources"` // field -> anime ID to take the value from
+}
+
+// ImportRequest is the request body for importing a metadata dump
+type ImportRequest struct {
+	Source string `json:"source"` // anilist, mal
+	File   string `json:"file"`   // file name inside the import directory
+	DryRun bool   `json:"dryRun"`
+}
+
 type AnimeListResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -35,3 +149,16 @@ type AnimeListResponse struct {
 		TotalPages int     `json:"total_pages"`
 	} `json:"data"`
 }
+
+// DuplicateCandidate is an existing anime that likely matches another entry
+type DuplicateCandidate struct {
+	Anime       Anime   `json:"anime"`
+	Score       float64 `json:"score"`       // 0-1 title similarity
+	MatchedName string  `json:"matchedName"` // the existing name that matched
+}
+
+// DuplicateCluster groups anime that likely describe the same show
+type DuplicateCluster struct {
+	Anime []Anime `json:"anime"`
+	Score float64 `json:"score"` // highest pairwise similarity in the cluster
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Anime history actions
+const (
+	HistoryActionMerge = "merge"
+)
+
+// AnimeHistoryEntry records an administrative operation on an anime
+type AnimeHistoryEntry struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AnimeID      string             `json:"animeId" bson:"animeId"`
+	Action       string             `json:"action" bson:"action"` // merge
+	UserID       string             `json:"userId" bson:"userId"`
+	Merged       []Anime            `json:"merged,omitempty" bson:"merged,omitempty"`             // snapshots of merged entries
+	FieldSources map[string]string  `json:"fieldSources,omitempty" bson:"fieldSources,omitempty"` // field -> anime ID the value was taken from
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// SlugRedirect points a retired slug at the anime that replaced it
+type SlugRedirect struct {
+	Slug      string    `json:"slug" bson:"_id"`
+	AnimeID   string    `json:"animeId" bson:"animeId"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
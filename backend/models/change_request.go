@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Change request actions
+const (
+	ChangeActionCreate = "create"
+	ChangeActionUpdate = "update"
+	ChangeActionDelete = "delete"
+)
+
+// Change request statuses
+const (
+	ChangeStatusPending  = "pending"
+	ChangeStatusApproved = "approved"
+	ChangeStatusRejected = "rejected"
+)
+
+// ChangeRequest is an anime change submitted by an editor while review mode is
+// enabled. It is applied only once an admin approves it.
+type ChangeRequest struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Action        string             `json:"action" bson:"action"`                       // create, update, delete
+	AnimeID       string             `json:"animeId,omitempty" bson:"animeId,omitempty"` // empty for create
+	Proposed      *Anime             `json:"proposed,omitempty" bson:"proposed,omitempty"`
+	Diff          []FieldChange      `json:"diff" bson:"diff"`
+	Status        string             `json:"status" bson:"status"` // pending, approved, rejected
+	SubmittedBy   string             `json:"submittedBy" bson:"submittedBy"`
+	ReviewedBy    string             `json:"reviewedBy,omitempty" bson:"reviewedBy,omitempty"`
+	ReviewComment string             `json:"reviewComment,omitempty" bson:"reviewComment,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+	ReviewedAt    *time.Time         `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+}
+
+// ReviewRequest is the request body for approving or rejecting a change request
+type ReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// FieldChange describes a single field difference in a change request
+type FieldChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment sort orders
+const (
+	CommentSortTop    = "top"
+	CommentSortNewest = "newest"
+)
+
+// MaxCommentDepth is how deep replies nest: comments on a page have depth 0,
+// replies to them depth 1 and replies to those depth 2
+const MaxCommentDepth = 2
+
+// CommentReactions are the reactions users can add to a comment
+var CommentReactions = []string{"like", "love", "laugh", "wow", "sad", "angry"}
+
+// Comment is a comment on an anime page or an episode page, or a reply to
+// another comment
+type Comment struct {
+	ID             primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	AnimeID        string              `json:"animeId" bson:"animeId"`
+	EpisodeID      *primitive.ObjectID `json:"episodeId,omitempty" bson:"episodeId,omitempty"` // set for comments on an episode
+	ParentID       *primitive.ObjectID `json:"parentId,omitempty" bson:"parentId,omitempty"`   // the comment replied to
+	RootID         *primitive.ObjectID `json:"rootId,omitempty" bson:"rootId,omitempty"`       // top-level comment of the thread
+	Depth          int                 `json:"depth" bson:"depth"`
+	UserID         string              `json:"userId" bson:"userId"`
+	AuthorName     string              `json:"authorName" bson:"authorName"` // display name when written
+	Body           string              `json:"body" bson:"body"`             // empty once deleted or hidden, for non-moderators
+	Spoiler        bool                `json:"spoiler" bson:"spoiler"`
+	Reactions      map[string][]string `json:"-" bson:"reactions,omitempty"` // IDs of the users per reaction
+	ReactionCounts map[string]int      `json:"reactionCounts" bson:"reactionCounts,omitempty"`
+	ReactionCount  int                 `json:"reactionCount" bson:"reactionCount"` // all reactions, ranks top comments
+	MyReactions    []string            `json:"myReactions,omitempty" bson:"-"`     // set for the signed-in user
+	ReplyCount     int                 `json:"replyCount" bson:"replyCount"`       // direct replies
+	Pinned         bool                `json:"pinned" bson:"pinned"`               // top-level only, listed first
+	Locked         bool                `json:"locked" bson:"locked"`               // top-level only, closes the thread to replies
+	Hidden         bool                `json:"hidden" bson:"hidden"`               // hidden by a moderator
+	HiddenBy       string              `json:"hiddenBy,omitempty" bson:"hiddenBy,omitempty"`
+	HideReason     string              `json:"hideReason,omitempty" bson:"hideReason,omitempty"`
+	Deleted        bool                `json:"deleted" bson:"deleted"` // deleted by its author while it had replies
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt" bson:"updatedAt"`
+	EditedAt       *time.Time          `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+}
+
+// CommentRequest is the request body for writing or editing a comment
+type CommentRequest struct {
+	Body    string `json:"body"`
+	Spoiler bool   `json:"spoiler"`
+}
+
+// ModerateCommentRequest is the request body for moderating a comment.
+// Fields left out are not changed; a reason is required to hide a comment.
+type ModerateCommentRequest struct {
+	Hidden *bool  `json:"hidden"`
+	Reason string `json:"reason"`
+	Locked *bool  `json:"locked"`
+	Pinned *bool  `json:"pinned"`
+}
+
+// IsCommentReaction reports whether reaction is a valid comment reaction
+func IsCommentReaction(reaction string) bool {
+	for _, r := range CommentReactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *CommentRequest) Validate() string {
+	if r.Body == "" {
+		return "Comment cannot be empty"
+	}
+
+	if len(r.Body) > 2000 {
+		return "Comment cannot be longer than 2000 characters"
+	}
+
+	return ""
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *ModerateCommentRequest) Validate() string {
+	if r.Hidden == nil && r.Locked == nil && r.Pinned == nil {
+		return "Nothing to change. Set hidden, locked or pinned"
+	}
+
+	if r.Hidden != nil && *r.Hidden && r.Reason == "" {
+		return "Reason is required to hide a comment"
+	}
+
+	return ""
+}
@@ -0,0 +1,19 @@
+package models
+
+// Envelope is the response body of every /api/v2 endpoint. Data holds the
+// resource itself (an object or an array) without an extra wrapping key.
+type Envelope struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// PageMeta describes the page returned by a paginated v2 list
+type PageMeta struct {
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalPages int `json:"totalPages"`
+}
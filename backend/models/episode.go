@@ -0,0 +1,184 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Episode types
+const (
+	EpisodeTypeRegular = "regular"
+	EpisodeTypeSpecial = "special" // numbered separately, e.g. SP1
+)
+
+type Episode struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AnimeID        string             `json:"animeId" bson:"animeId"`
+	Type           string             `json:"type" bson:"type"`                                         // regular, special
+	Season         int                `json:"season" bson:"season"`                                     // 1-based
+	Number         float64            `json:"number" bson:"number"`                                     // within season and type, e.g. 12.5 for a recap
+	AbsoluteNumber float64            `json:"absoluteNumber,omitempty" bson:"absoluteNumber,omitempty"` // across seasons and cours
+	Label          string             `json:"label" bson:"-"`                                           // display number, set by EpisodeLabel
+	Title          string             `json:"title" bson:"title"`
+	Synopsis       string             `json:"synopsis" bson:"synopsis"`
+	AirDate        *time.Time         `json:"airDate,omitempty" bson:"airDate,omitempty"`
+	Duration       int                `json:"duration" bson:"duration"` // seconds
+	ThumbnailUrl   string             `json:"thumbnailUrl" bson:"thumbnailUrl"`
+	IsFiller       bool               `json:"isFiller" bson:"isFiller"`
+	IsRecap        bool               `json:"isRecap" bson:"isRecap"`
+	Sources        []VideoSource      `json:"sources" bson:"sources"`
+	Subtitles      []SubtitleTrack    `json:"subtitles" bson:"subtitles"`
+	Markers        []SkipMarker       `json:"markers" bson:"markers"`                 // intro/outro skip ranges
+	PublicAt       time.Time          `json:"publicAt" bson:"publicAt"`               // release to everyone
+	VipAt          *time.Time         `json:"vipAt,omitempty" bson:"vipAt,omitempty"` // earlier release to early access roles
+	Published      bool               `json:"published" bson:"published"`             // set once PublicAt has passed
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// EpisodeListResponse is a page of episodes, returned when the episode list
+// is requested with page or limit
+type EpisodeListResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Data    struct {
+		Data       []Episode `json:"data"`
+		Total      int       `json:"total"`
+		Page       int       `json:"page"`
+		Limit      int       `json:"limit"`
+		TotalPages int       `json:"total_pages"`
+	} `json:"data"`
+}
+
+// EpisodeRef points to a neighbouring episode for previous/next navigation
+type EpisodeRef struct {
+	ID    primitive.ObjectID `json:"id"`
+	Label string             `json:"label"`
+	Title string             `json:"title"`
+	Href  string             `json:"href"`
+}
+
+// EpisodeNavigation links an episode to the episodes before and after it
+type EpisodeNavigation struct {
+	Previous *EpisodeRef `json:"previous"`
+	Next     *EpisodeRef `json:"next"`
+}
+
+// LatestEpisodesCard is an entry of the latest episodes rail: the episodes of
+// one anime released together, in display order
+type LatestEpisodesCard struct {
+	Anime      AnimeRef     `json:"anime"`
+	Episodes   []EpisodeRef `json:"episodes"`
+	ReleasedAt time.Time    `json:"releasedAt"` // newest episode of the card
+}
+
+// EpisodeRequest is the request body for creating or updating an episode
+type EpisodeRequest struct {
+	AnimeID        string     `json:"animeId"`
+	Type           string     `json:"type"`
+	Season         int        `json:"season"`
+	Number         float64    `json:"number"`
+	AbsoluteNumber float64    `json:"absoluteNumber"`
+	Title          string     `json:"title"`
+	Synopsis       string     `json:"synopsis"`
+	AirDate        *time.Time `json:"airDate"`
+	Duration       int        `json:"duration"`
+	ThumbnailUrl   string     `json:"thumbnailUrl"`
+	IsFiller       bool       `json:"isFiller"`
+	IsRecap        bool       `json:"isRecap"`
+	PublicAt       *time.Time `json:"publicAt"` // defaults to now on create, unchanged on update
+	VipAt          *time.Time `json:"vipAt"`
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *EpisodeRequest) Validate() string {
+	if !primitive.IsValidObjectID(r.AnimeID) {
+		return "animeId must be a valid anime ID"
+	}
+
+	if r.Type != "" && r.Type != EpisodeTypeRegular && r.Type != EpisodeTypeSpecial {
+		return "Invalid episode type. Must be: regular or special"
+	}
+
+	if r.Season < 0 {
+		return "Season cannot be negative"
+	}
+
+	if r.Number <= 0 {
+		return "Episode number must be greater than 0"
+	}
+
+	if r.AbsoluteNumber < 0 {
+		return "Absolute number cannot be negative"
+	}
+
+	if r.Duration < 0 {
+		return "Duration cannot be negative"
+	}
+
+	// Without publicAt the order is checked against the effective release
+	// time, which only the handler knows on updates
+	if r.VipAt != nil && r.PublicAt != nil && !r.VipAt.Before(*r.PublicAt) {
+		return "vipAt must be before publicAt"
+	}
+
+	return ""
+}
+
+// ToEpisode builds an episode document from the request. Episodes default
+// to regular episodes of the first season, released now.
+func (r *EpisodeRequest) ToEpisode() Episode {
+	now := time.Now()
+	publicAt := now
+	if r.PublicAt != nil {
+		publicAt = *r.PublicAt
+	}
+
+	episodeType := r.Type
+	if episodeType == "" {
+		episodeType = EpisodeTypeRegular
+	}
+	season := r.Season
+	if season == 0 {
+		season = 1
+	}
+
+	return Episode{
+		AnimeID:        r.AnimeID,
+		Type:           episodeType,
+		Season:         season,
+		Number:         r.Number,
+		AbsoluteNumber: r.AbsoluteNumber,
+		Title:          r.Title,
+		Synopsis:       r.Synopsis,
+		AirDate:        r.AirDate,
+		Duration:       r.Duration,
+		ThumbnailUrl:   r.ThumbnailUrl,
+		IsFiller:       r.IsFiller,
+		IsRecap:        r.IsRecap,
+		PublicAt:       publicAt,
+		VipAt:          r.VipAt,
+		Published:      !publicAt.After(now),
+	}
+}
+
+// EpisodeSort orders episodes for display: regular episodes before specials,
+// then by season and number
+var EpisodeSort = bson.D{{Key: "type", Value: 1}, {Key: "season", Value: 1}, {Key: "number", Value: 1}}
+
+// EpisodeLabel returns the display number of an episode: "12.5" for a
+// single-season show, "S2E3" for later seasons and "SP1" for specials
+func EpisodeLabel(episode *Episode) string {
+	number := strconv.FormatFloat(episode.Number, 'f', -1, 64)
+	switch {
+	case episode.Type == EpisodeTypeSpecial:
+		return "SP" + number
+	case episode.Season > 1:
+		return "S" + strconv.Itoa(episode.Season) + "E" + number
+	}
+	return number
+}
@@ -0,0 +1,8 @@
+package models
+
+// GraphQLRequest is a GraphQL query sent over HTTP
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
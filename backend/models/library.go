@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Library watch statuses
+const (
+	LibraryWatching    = "watching"
+	LibraryCompleted   = "completed"
+	LibraryOnHold      = "on_hold"
+	LibraryDropped     = "dropped"
+	LibraryPlanToWatch = "plan_to_watch"
+)
+
+// LibraryStatuses lists the watch statuses in the order shown on profiles
+var LibraryStatuses = []string{
+	LibraryWatching,
+	LibraryCompleted,
+	LibraryOnHold,
+	LibraryDropped,
+	LibraryPlanToWatch,
+}
+
+// LibraryEntry is an anime on a user's personal list. A user has at most one
+// entry per anime.
+type LibraryEntry struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     string             `json:"userId" bson:"userId"`
+	AnimeID    string             `json:"animeId" bson:"animeId"`
+	Status     string             `json:"status" bson:"status"`     // watching, completed, on_hold, dropped, plan_to_watch
+	Progress   int                `json:"progress" bson:"progress"` // episodes watched
+	Score      int                `json:"score" bson:"score"`       // the user's rating, 1-10, 0 if not rated
+	StartedAt  *time.Time         `json:"startedAt,omitempty" bson:"startedAt,omitempty"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+	Notes      string             `json:"notes" bson:"notes"`
+	Anime      *AnimeRef          `json:"anime,omitempty" bson:"-"` // set when listing the library
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// LibraryEntryRequest is the request body for adding or updating an anime on
+// the user's library
+type LibraryEntryRequest struct {
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	Score      *int       `json:"score"` // leave out to keep the current rating, 0 clears it
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	Notes      string     `json:"notes"`
+}
+
+// LibraryCounts is the number of library entries per watch status
+type LibraryCounts struct {
+	Counts map[string]int `json:"counts"` // status -> entries, every status present
+	Total  int            `json:"total"`
+}
+
+// IsLibraryStatus reports whether status is a valid watch status
+func IsLibraryStatus(status string) bool {
+	for _, s := range LibraryStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid. episodeCount is the expected episode
+// total of the anime, 0 if unknown.
+func (r *LibraryEntryRequest) Validate(episodeCount int) string {
+	if !IsLibraryStatus(r.Status) {
+		return "Invalid status. Must be: watching, completed, on_hold, dropped, or plan_to_watch"
+	}
+
+	if r.Progress < 0 {
+		return "Progress cannot be negative"
+	}
+
+	if episodeCount > 0 && r.Progress > episodeCount {
+		return "Progress cannot exceed the episode count of the anime"
+	}
+
+	if r.Score != nil && (*r.Score < 0 || *r.Score > 10) {
+		return "Score must be between 1 and 10, or 0 to clear it"
+	}
+
+	if r.StartedAt != nil && r.FinishedAt != nil && r.FinishedAt.Before(*r.StartedAt) {
+		return "Finish date cannot be before start date"
+	}
+
+	if len(r.Notes) > 2000 {
+		return "Notes cannot be longer than 2000 characters"
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types
+const (
+	NotificationNewEpisode     = "new_episode"     // an episode of a followed anime was released
+	NotificationNewSource      = "new_source"      // an episode of a followed anime got a new source
+	NotificationStatusChange   = "status_change"   // a followed anime changed status
+	NotificationSourceDisabled = "source_disabled" // editors: a source was disabled by user reports
+)
+
+// Follow subscribes a user to the notifications of an anime. A user follows
+// an anime at most once.
+type Follow struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    string             `json:"userId" bson:"userId"`
+	AnimeID   string             `json:"animeId" bson:"animeId"`
+	Anime     *AnimeRef          `json:"anime,omitempty" bson:"-"` // set when listing follows
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Notification is an entry of a user's inbox. It is also pushed over the
+// websocket as {"type": "notification", "event": <type>, "data": <notification>}.
+type Notification struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID       string             `json:"userId" bson:"userId"`
+	Type         string             `json:"type" bson:"type"` // new_episode, new_source, status_change, source_disabled
+	AnimeID      string             `json:"animeId,omitempty" bson:"animeId,omitempty"`
+	AnimeTitle   string             `json:"animeTitle,omitempty" bson:"animeTitle,omitempty"` // when the notification was sent
+	EpisodeID    string             `json:"episodeId,omitempty" bson:"episodeId,omitempty"`
+	EpisodeLabel string             `json:"episodeLabel,omitempty" bson:"episodeLabel,omitempty"`
+	SourceID     string             `json:"sourceId,omitempty" bson:"sourceId,omitempty"`
+	Status       string             `json:"status,omitempty" bson:"status,omitempty"` // new anime status of status_change
+	Message      string             `json:"message" bson:"message"`
+	Read         bool               `json:"read" bson:"read"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	ReadAt       *time.Time         `json:"readAt,omitempty" bson:"readAt,omitempty"`
+}
@@ -33,6 +33,17 @@ var (
 	PermDeleteAnime = "delete_anime"
 	PermListAnime   = "list_anime"
 
+	// Episode Permissions
+	PermDownloadEpisodes = "download_episodes"
+	PermEarlyAccess      = "early_access" // watch episodes from their vipAt time
+
+	// Editorial Review Permissions
+	PermReviewChanges = "review_changes"
+
+	// Community Permissions
+	PermModerateReviews  = "moderate_reviews"
+	PermModerateComments = "moderate_comments"
+
 	// Slider Management Permissions
 	PermManageSlider = "manage_slider"
 
@@ -57,6 +68,13 @@ var DefaultRolePermissions = map[string][]string{
 		PermEditAnime,
 		PermDeleteAnime,
 		PermListAnime,
+		// Episodes
+		PermDownloadEpisodes,
+		// Editorial Review
+		PermReviewChanges,
+		// Community
+		PermModerateReviews,
+		PermModerateComments,
 		// Slider Management
 		PermManageSlider,
 		// System
@@ -74,6 +92,11 @@ var DefaultRolePermissions = map[string][]string{
 		PermEditAnime,
 		PermDeleteAnime,
 		PermListAnime,
+		// Episodes
+		PermDownloadEpisodes,
+		// Community
+		PermModerateReviews,
+		PermModerateComments,
 		// Slider Management
 		PermManageSlider,
 		// System
@@ -85,6 +108,9 @@ var DefaultRolePermissions = map[string][]string{
 		PermReadUser,
 		PermListAnime,
 		PermViewDashboard,
+		// Episodes
+		PermDownloadEpisodes,
+		PermEarlyAccess,
 	},
 	"user": {
 		// View Only
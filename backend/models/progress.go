@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WatchProgress is a user's playback position in an episode. A user has at
+// most one per episode.
+type WatchProgress struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    string             `json:"userId" bson:"userId"`
+	EpisodeID primitive.ObjectID `json:"episodeId" bson:"episodeId"`
+	AnimeID   string             `json:"animeId" bson:"animeId"`
+	Position  float64            `json:"position" bson:"position"` // seconds
+	Duration  float64            `json:"duration" bson:"duration"` // seconds, as reported by the player
+	Watched   bool               `json:"watched" bson:"watched"`   // stays set once reached
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ProgressRequest is a playback heartbeat sent by the player, over HTTP or
+// as a "progress" websocket message
+type ProgressRequest struct {
+	EpisodeID string  `json:"episodeId,omitempty"` // websocket messages only
+	Position  float64 `json:"position"`
+	Duration  float64 `json:"duration"`
+}
+
+// ContinueWatchingItem is the episode to play next for an anime the user
+// is watching
+type ContinueWatchingItem struct {
+	Anime     AnimeRef   `json:"anime"`
+	Episode   EpisodeRef `json:"episode"`
+	Position  float64    `json:"position"`  // seconds to resume at, 0 for the episode after a watched one
+	Duration  float64    `json:"duration"`  // 0 for the episode after a watched one
+	UpdatedAt time.Time  `json:"updatedAt"` // last playback activity on the anime
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *ProgressRequest) Validate() string {
+	if r.Position < 0 {
+		return "Position cannot be negative"
+	}
+
+	if r.Duration < 0 {
+		return "Duration cannot be negative"
+	}
+
+	if r.Duration > 0 && r.Position > r.Duration {
+		return "Position cannot be past the duration"
+	}
+
+	return ""
+}
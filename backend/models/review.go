@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Review sort orders
+const (
+	ReviewSortHelpful = "helpful"
+	ReviewSortRecent  = "recent"
+)
+
+// Rating is a user's score for an anime. A user has at most one rating per
+// anime; it is also shown as the score of their library entry.
+type Rating struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    string             `json:"userId" bson:"userId"`
+	AnimeID   string             `json:"animeId" bson:"animeId"`
+	Score     int                `json:"score" bson:"score"` // 1-10
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// RatingRequest is the request body for rating an anime
+type RatingRequest struct {
+	Score int `json:"score"`
+}
+
+// Review is a user's written review of an anime. A user has at most one
+// review per anime; writing it also rates the anime with its score.
+type Review struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AnimeID      string             `json:"animeId" bson:"animeId"`
+	UserID       string             `json:"userId" bson:"userId"`
+	AuthorName   string             `json:"authorName" bson:"authorName"` // display name when written
+	Score        int                `json:"score" bson:"score"`           // 1-10
+	Title        string             `json:"title" bson:"title"`
+	Body         string             `json:"body" bson:"body"`
+	Spoiler      bool               `json:"spoiler" bson:"spoiler"`
+	HelpfulBy    []string           `json:"-" bson:"helpfulBy"` // IDs of users who found the review helpful
+	HelpfulCount int                `json:"helpfulCount" bson:"helpfulCount"`
+	Removed      bool               `json:"removed" bson:"removed"` // hidden by a moderator
+	RemovedBy    string             `json:"removedBy,omitempty" bson:"removedBy,omitempty"`
+	RemoveReason string             `json:"removeReason,omitempty" bson:"removeReason,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+	EditableTo   *time.Time         `json:"editableTo,omitempty" bson:"-"` // end of the edit window, set for the author
+}
+
+// AnimeReviewRequest is the request body for writing or editing a review of
+// an anime
+type AnimeReviewRequest struct {
+	Score   int    `json:"score"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Spoiler bool   `json:"spoiler"`
+}
+
+// RemoveReviewRequest is the request body for removing a review as a
+// moderator
+type RemoveReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ValidScore reports whether score is a valid rating
+func ValidScore(score int) bool {
+	return score >= 1 && score <= 10
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *RatingRequest) Validate() string {
+	if !ValidScore(r.Score) {
+		return "Score must be between 1 and 10"
+	}
+	return ""
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *AnimeReviewRequest) Validate() string {
+	if !ValidScore(r.Score) {
+		return "Score must be between 1 and 10"
+	}
+
+	if r.Title == "" {
+		return "Title is required"
+	}
+
+	if len(r.Title) > 150 {
+		return "Title cannot be longer than 150 characters"
+	}
+
+	if len(r.Body) < 100 {
+		return "Review must be at least 100 characters"
+	}
+
+	if len(r.Body) > 10000 {
+		return "Review cannot be longer than 10000 characters"
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package models
+
+// SeoMeta is the page metadata for an anime detail page
+type SeoMeta struct {
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	CanonicalURL string                 `json:"canonicalUrl"`
+	Image        *SeoImage              `json:"image,omitempty"`
+	OpenGraph    OpenGraphMeta          `json:"openGraph"`
+	Twitter      TwitterMeta            `json:"twitter"`
+	JSONLD       map[string]interface{} `json:"jsonLd"` // schema.org TVSeries or Movie
+}
+
+// SeoImage is a page image with its pixel dimensions (0 when unknown)
+type SeoImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Alt    string `json:"alt"`
+}
+
+// OpenGraphMeta holds OpenGraph tags keyed by property name
+type OpenGraphMeta struct {
+	Title       string `json:"og:title"`
+	Type        string `json:"og:type"` // video.tv_show, video.movie
+	URL         string `json:"og:url"`
+	Description string `json:"og:description"`
+	SiteName    string `json:"og:site_name"`
+	Locale      string `json:"og:locale"`
+	Image       string `json:"og:image,omitempty"`
+	ImageWidth  int    `json:"og:image:width,omitempty"`
+	ImageHeight int    `json:"og:image:height,omitempty"`
+	ImageAlt    string `json:"og:image:alt,omitempty"`
+}
+
+// TwitterMeta holds Twitter card tags keyed by name
+type TwitterMeta struct {
+	Card        string `json:"twitter:card"`
+	Title       string `json:"twitter:title"`
+	Description string `json:"twitter:description"`
+	Image       string `json:"twitter:image,omitempty"`
+	ImageAlt    string `json:"twitter:image:alt,omitempty"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Skip marker types
+const (
+	MarkerTypeOpening = "opening"
+	MarkerTypeEnding  = "ending"
+	MarkerTypeRecap   = "recap"
+	MarkerTypePreview = "preview"
+)
+
+// Skip marker sources
+const (
+	MarkerSourceEditor    = "editor"
+	MarkerSourceCommunity = "community" // accepted from user submissions by vote
+)
+
+// Marker submission statuses
+const (
+	SubmissionStatusPending  = "pending"
+	SubmissionStatusAccepted = "accepted"
+)
+
+var validMarkerTypes = map[string]bool{
+	MarkerTypeOpening: true,
+	MarkerTypeEnding:  true,
+	MarkerTypeRecap:   true,
+	MarkerTypePreview: true,
+}
+
+// SkipMarker is a skippable time range of an episode, in seconds
+type SkipMarker struct {
+	Type   string  `json:"type" bson:"type"` // opening, ending, recap, preview
+	Start  float64 `json:"start" bson:"start"`
+	End    float64 `json:"end" bson:"end"`
+	Source string  `json:"source" bson:"source"` // editor, community
+}
+
+// MarkersRequest is the request body for replacing the skip markers of an episode
+type MarkersRequest struct {
+	Markers []SkipMarker `json:"markers"`
+}
+
+// MarkerSubmission is a skip marker proposed by a user, accepted onto the
+// episode once its vote score reaches the configured threshold
+type MarkerSubmission struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EpisodeID primitive.ObjectID `json:"episodeId" bson:"episodeId"`
+	UserID    string             `json:"userId" bson:"userId"`
+	Type      string             `json:"type" bson:"type"`
+	Start     float64            `json:"start" bson:"start"`
+	End       float64            `json:"end" bson:"end"`
+	Votes     []MarkerVote       `json:"-" bson:"votes"`
+	Score     int                `json:"score" bson:"score"`   // upvotes minus downvotes
+	Status    string             `json:"status" bson:"status"` // pending, accepted
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// MarkerVote is one user's vote on a marker submission
+type MarkerVote struct {
+	UserID string `json:"userId" bson:"userId"`
+	Value  int    `json:"value" bson:"value"` // 1 or -1
+}
+
+// MarkerVoteRequest is the request body for voting on a marker submission
+type MarkerVoteRequest struct {
+	Value int `json:"value"` // 1 to confirm, -1 to dispute
+}
+
+// Validate checks a marker against the episode duration (0 if unknown) and
+// returns an error message, or an empty string if it is valid
+func (m *SkipMarker) Validate(duration int) string {
+	if !validMarkerTypes[m.Type] {
+		return "Invalid marker type. Must be: opening, ending, recap, or preview"
+	}
+
+	if m.Start < 0 {
+		return "Marker start cannot be negative"
+	}
+
+	if m.End <= m.Start {
+		return "Marker end must be after its start"
+	}
+
+	if duration > 0 && m.End > float64(duration) {
+		return "Marker end is past the end of the episode"
+	}
+
+	return ""
+}
+
+// ToMarker returns the marker a submission proposes
+func (s *MarkerSubmission) ToMarker() SkipMarker {
+	return SkipMarker{Type: s.Type, Start: s.Start, End: s.End, Source: MarkerSourceCommunity}
+}
+
+// SortMarkers orders markers by start time
+func SortMarkers(markers []SkipMarker) {
+	sort.SliceStable(markers, func(i, j int) bool {
+		return markers[i].Start < markers[j].Start
+	})
+}
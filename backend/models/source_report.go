@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Source report reasons
+const (
+	ReportReasonNotPlaying    = "not_playing"
+	ReportReasonWrongEpisode  = "wrong_episode"
+	ReportReasonBadQuality    = "bad_quality"
+	ReportReasonWrongLanguage = "wrong_language"
+	ReportReasonOther         = "other"
+)
+
+// Source report statuses
+const (
+	ReportStatusOpen     = "open"
+	ReportStatusResolved = "resolved"
+)
+
+var validReportReasons = map[string]bool{
+	ReportReasonNotPlaying:    true,
+	ReportReasonWrongEpisode:  true,
+	ReportReasonBadQuality:    true,
+	ReportReasonWrongLanguage: true,
+	ReportReasonOther:         true,
+}
+
+// SourceReport is a user's report that a video source of an episode is
+// broken. A user has at most one open report per source.
+type SourceReport struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EpisodeID  primitive.ObjectID `json:"episodeId" bson:"episodeId"`
+	SourceID   string             `json:"sourceId" bson:"sourceId"`
+	Reason     string             `json:"reason" bson:"reason"` // not_playing, wrong_episode, bad_quality, wrong_language, other
+	Details    string             `json:"details,omitempty" bson:"details,omitempty"`
+	ReporterID string             `json:"reporterId" bson:"reporterId"`
+	Status     string             `json:"status" bson:"status"` // open, resolved
+	ResolvedBy string             `json:"resolvedBy,omitempty" bson:"resolvedBy,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	ResolvedAt *time.Time         `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+}
+
+// SourceReportRequest is the request body for reporting a video source
+type SourceReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// ReportedSource is an entry of the moderation queue: the open reports of
+// one source, grouped
+type ReportedSource struct {
+	EpisodeID     primitive.ObjectID `json:"episodeId"`
+	SourceID      string             `json:"sourceId"`
+	AnimeID       string             `json:"animeId"`
+	EpisodeLabel  string             `json:"episodeLabel"`
+	Source        *VideoSource       `json:"source"` // nil if the source was removed
+	ReportCount   int                `json:"reportCount"`
+	Reasons       map[string]int     `json:"reasons"` // reason -> count
+	FirstReportAt time.Time          `json:"firstReportAt"`
+	LastReportAt  time.Time          `json:"lastReportAt"`
+}
+
+// Validate checks the request fields and returns an error message, or an
+// empty string if the request is valid
+func (r *SourceReportRequest) Validate() string {
+	if !validReportReasons[r.Reason] {
+		return "Invalid reason. Must be: not_playing, wrong_episode, bad_quality, wrong_language, or other"
+	}
+
+	if r.Reason == ReportReasonOther && r.Details == "" {
+		return "Details are required for other"
+	}
+
+	if len(r.Details) > 500 {
+		return "Details cannot be longer than 500 characters"
+	}
+
+	return ""
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// SubtitleTrack is a WebVTT subtitle of an episode, converted from the
+// uploaded original
+type SubtitleTrack struct {
+	ID          string    `json:"id" bson:"id"`
+	Language    string    `json:"language" bson:"language"` // BCP 47 tag, e.g. ar, en
+	Label       string    `json:"label" bson:"label"`       // shown in the player menu
+	Format      string    `json:"format" bson:"format"`     // format of the original: srt, ass, ssa, vtt
+	URL         string    `json:"url" bson:"url"`           // converted WebVTT file
+	OriginalURL string    `json:"originalUrl" bson:"originalUrl"`
+	IsDefault   bool      `json:"isDefault" bson:"isDefault"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+var languageTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ValidLanguageTag reports whether tag looks like a BCP 47 language tag
+func ValidLanguageTag(tag string) bool {
+	return languageTagPattern.MatchString(tag)
+}
+
+// subtitleLabels are the default player labels of common subtitle languages
+var subtitleLabels = map[string]string{
+	"ar": "العربية",
+	"en": "English",
+	"ja": "日本語",
+	"fr": "Français",
+}
+
+// DefaultSubtitleLabel returns the label used when an upload does not name
+// its track
+func DefaultSubtitleLabel(language string) string {
+	if label, ok := subtitleLabels[language]; ok {
+		return label
+	}
+	return language
+}
+
+// SortSubtitles puts the default track first, then orders tracks by language
+func SortSubtitles(tracks []SubtitleTrack) {
+	sort.SliceStable(tracks, func(i, j int) bool {
+		if tracks[i].IsDefault != tracks[j].IsDefault {
+			return tracks[i].IsDefault
+		}
+		return tracks[i].Language < tracks[j].Language
+	})
+}
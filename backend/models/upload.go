@@ -0,0 +1,6 @@
+package models
+
+// DeleteCoverRequest is the request body for deleting an uploaded cover
+type DeleteCoverRequest struct {
+	URL string `json:"url"`
+}
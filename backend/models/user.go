@@ -29,6 +29,17 @@ type RegisterRequest struct {
 	Password    string `json:"password" validate:"required,min=8"`
 }
 
+type CreateUserRequest struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Role        string `json:"role"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
 type LoginResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
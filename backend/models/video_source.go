@@ -0,0 +1,138 @@
+package models
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Video source types
+const (
+	SourceTypeHLS   = "hls"
+	SourceTypeMP4   = "mp4"
+	SourceTypeEmbed = "embed" // iframe player on a third-party host
+)
+
+// Video source languages
+const (
+	SourceLanguageSub = "sub"
+	SourceLanguageDub = "dub"
+)
+
+var validSourceQualities = map[string]bool{"360p": true, "480p": true, "720p": true, "1080p": true}
+
+// VideoSource is one playback option of an episode
+type VideoSource struct {
+	ID            string `json:"id" bson:"id"`
+	Server        string `json:"server" bson:"server"` // host or mirror name shown to users
+	Type          string `json:"type" bson:"type"`     // hls, mp4, embed
+	URL           string `json:"url" bson:"url"`
+	Quality       string `json:"quality" bson:"quality"`             // 360p, 480p, 720p, 1080p
+	Language      string `json:"language" bson:"language"`           // sub, dub
+	AudioLanguage string `json:"audioLanguage" bson:"audioLanguage"` // e.g. ja, en, ar
+	Priority      int    `json:"priority" bson:"priority"`           // lower plays first
+	Disabled      bool   `json:"disabled" bson:"disabled"`
+}
+
+// SourcesRequest is the request body for replacing the sources of an episode
+type SourcesRequest struct {
+	Sources []VideoSource `json:"sources"`
+}
+
+// ReorderSourcesRequest lists source IDs in their new playback order
+type ReorderSourcesRequest struct {
+	SourceIDs []string `json:"sourceIds"`
+}
+
+// SourceStatusRequest enables or disables a single source
+type SourceStatusRequest struct {
+	Disabled bool `json:"disabled"`
+}
+
+// Validate checks a source and returns an error message, or an empty string
+// if it is valid. Embed players must be served from an allowed host.
+func (s *VideoSource) Validate(embedHosts []string) string {
+	if s.Server == "" {
+		return "Source server is required"
+	}
+
+	switch s.Type {
+	case SourceTypeHLS, SourceTypeMP4, SourceTypeEmbed:
+	default:
+		return "Invalid source type. Must be: hls, mp4, or embed"
+	}
+
+	if !validSourceQualities[s.Quality] {
+		return "Invalid source quality. Must be: 360p, 480p, 720p, or 1080p"
+	}
+
+	if s.Language != SourceLanguageSub && s.Language != SourceLanguageDub {
+		return "Invalid source language. Must be: sub or dub"
+	}
+
+	parsed, err := url.Parse(s.URL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return "Source URL must be an absolute http(s) URL"
+	}
+
+	if s.Type == SourceTypeEmbed {
+		if parsed.Scheme != "https" {
+			return "Embed sources must use https"
+		}
+		if !hostAllowed(parsed.Hostname(), embedHosts) {
+			return "Embed host " + parsed.Hostname() + " is not allowed"
+		}
+	}
+
+	return ""
+}
+
+// hostAllowed reports whether host is an allowed host or one of its subdomains
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" && (host == entry || strings.HasSuffix(host, "."+entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortSources orders sources for playback: enabled before disabled, then by
+// priority, then by quality from highest to lowest
+func SortSources(sources []VideoSource) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		if a.Disabled != b.Disabled {
+			return !a.Disabled
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return qualityRank(a.Quality) > qualityRank(b.Quality)
+	})
+}
+
+// PlayableSources returns the enabled sources in playback order
+func PlayableSources(sources []VideoSource) []VideoSource {
+	playable := []VideoSource{}
+	for _, source := range sources {
+		if !source.Disabled {
+			playable = append(playable, source)
+		}
+	}
+	SortSources(playable)
+	return playable
+}
+
+func qualityRank(quality string) int {
+	rank := 0
+	for _, r := range quality {
+		if r < '0' || r > '9' {
+			break
+		}
+		rank = rank*10 + int(r-'0')
+	}
+	return rank
+}
@@ -0,0 +1,311 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Info describes the API in the generated document
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Param is a query parameter of an operation
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Operation documents one registered route. Data is wrapped in the standard
+// {success, message, data} envelope; Body is used as the raw response body.
+type Operation struct {
+	Method      string
+	Path        string // route path as registered with fiber
+	Summary     string
+	Tags        []string
+	Auth        bool
+	Deprecated  bool
+	Query       []Param
+	Request     interface{} // request body value, described by its type
+	Files       []string    // multipart file fields, used instead of Request
+	Data        interface{}
+	Meta        interface{} // pagination metadata next to data in the envelope
+	Body        interface{}
+	Status      int    // success status, defaults to 200
+	ContentType string // success content type, defaults to application/json
+}
+
+// Spec holds the documented operations and builds OpenAPI 3 documents from
+// the routes registered on a fiber app
+type Spec struct {
+	info       Info
+	operations map[string]Operation
+	order      []string
+}
+
+func New(info Info, operations []Operation) *Spec {
+	spec := &Spec{info: info, operations: map[string]Operation{}}
+	for _, op := range operations {
+		key := Key(op.Method, op.Path)
+		if _, exists := spec.operations[key]; !exists {
+			spec.order = append(spec.order, key)
+		}
+		spec.operations[key] = op
+	}
+	return spec
+}
+
+// Key identifies a route by method and path
+func Key(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// documentedRoutes returns the keys of routes that should be documented:
+// everything except middleware and the HEAD routes fiber adds automatically
+func documentedRoutes(routes []fiber.Route) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodConnect || route.Method == fiber.MethodTrace {
+			continue
+		}
+		key := Key(route.Method, route.Path)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Undocumented returns the registered routes that have no operation
+func (s *Spec) Undocumented(routes []fiber.Route) []string {
+	missing := []string{}
+	for _, key := range documentedRoutes(routes) {
+		if _, ok := s.operations[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Unregistered returns the operations that do not match any registered route
+func (s *Spec) Unregistered(routes []fiber.Route) []string {
+	registered := map[string]bool{}
+	for _, key := range documentedRoutes(routes) {
+		registered[key] = true
+	}
+	stale := []string{}
+	for _, key := range s.order {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	return stale
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`:(\w+)\??`)
+	wildcardPattern = regexp.MustCompile(`[*+]`)
+)
+
+// openAPIPath converts a fiber route path to an OpenAPI path template and
+// returns its path parameter names
+func openAPIPath(path string) (string, []string) {
+	params := []string{}
+	converted := paramPattern.ReplaceAllStringFunc(path, func(match string) string {
+		name := paramPattern.FindStringSubmatch(match)[1]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	converted = wildcardPattern.ReplaceAllStringFunc(converted, func(string) string {
+		params = append(params, "path")
+		return "{path}"
+	})
+	return converted, params
+}
+
+// Document builds the OpenAPI document for the documented operations that
+// are registered on the app, in registration order
+func (s *Spec) Document(routes []fiber.Route) map[string]interface{} {
+	schemas := newSchemaRegistry()
+	paths := map[string]map[string]interface{}{}
+
+	for _, key := range documentedRoutes(routes) {
+		op, ok := s.operations[key]
+		if !ok {
+			continue
+		}
+
+		path, pathParams := openAPIPath(op.Path)
+		operation := map[string]interface{}{
+			"summary":     op.Summary,
+			"operationId": operationID(op.Method, path),
+		}
+		if len(op.Tags) > 0 {
+			operation["tags"] = op.Tags
+		}
+		if op.Deprecated {
+			operation["deprecated"] = true
+		}
+		if op.Auth {
+			operation["security"] = []map[string][]string{{"bearerAuth": {}}}
+		}
+
+		parameters := []map[string]interface{}{}
+		for _, name := range pathParams {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, param := range op.Query {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        param.Name,
+				"in":          "query",
+				"required":    param.Required,
+				"description": param.Description,
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		if len(op.Files) > 0 {
+			fields := map[string]interface{}{}
+			for _, name := range op.Files {
+				fields[name] = map[string]interface{}{"type": "string", "format": "binary"}
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					fiber.MIMEMultipartForm: map[string]interface{}{
+						"schema": map[string]interface{}{"type": "object", "properties": fields, "required": op.Files},
+					},
+				},
+			}
+		} else if op.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schemaFor(op.Request)},
+				},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = fiber.StatusOK
+		}
+		contentType := op.ContentType
+		if contentType == "" {
+			contentType = fiber.MIMEApplicationJSON
+		}
+
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		var body map[string]interface{}
+		switch {
+		case op.Body != nil:
+			body = schemas.schemaFor(op.Body)
+		case op.Data != nil:
+			var meta map[string]interface{}
+			if op.Meta != nil {
+				meta = schemas.schemaFor(op.Meta)
+			}
+			body = envelopeSchema(schemas.schemaFor(op.Data), meta)
+		case contentType == fiber.MIMEApplicationJSON && status != fiber.StatusSwitchingProtocols:
+			body = envelopeSchema(nil, nil)
+		}
+		switch {
+		case status == fiber.StatusSwitchingProtocols:
+			// Protocol upgrades have no response body
+		case body != nil:
+			success["content"] = map[string]interface{}{contentType: map[string]interface{}{"schema": body}}
+		default:
+			success["content"] = map[string]interface{}{contentType: map[string]interface{}{}}
+		}
+
+		operation["responses"] = map[string]interface{}{
+			fmt.Sprint(status): success,
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"$ref": "#/components/schemas/ErrorResponse"},
+					},
+				},
+			},
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	schemas.components["ErrorResponse"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"success": map[string]interface{}{"type": "boolean"},
+			"message": map[string]interface{}{"type": "string"},
+			"error":   map[string]interface{}{"type": "string"},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       s.info.Title,
+			"version":     s.info.Version,
+			"description": s.info.Description,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// envelopeSchema wraps data and meta schemas in the standard response envelope
+func envelopeSchema(data, meta map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"success": map[string]interface{}{"type": "boolean"},
+		"message": map[string]interface{}{"type": "string"},
+	}
+	if data != nil {
+		properties["data"] = data
+	}
+	if meta != nil {
+		properties["meta"] = meta
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+var nonWordPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationID derives a stable identifier such as "get_api_anime_id"
+func operationID(method, path string) string {
+	return strings.ToLower(method) + "_" + strings.Trim(nonWordPattern.ReplaceAllString(path, "_"), "_")
+}
@@ -0,0 +1,581 @@
+package routes
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/controllers"
+	"toofy-backend/importer"
+	"toofy-backend/models"
+	"toofy-backend/openapi"
+)
+
+// Response data shapes for handlers that build their payload with fiber.Map
+type (
+	animeData struct {
+		Anime models.Anime `json:"anime"`
+	}
+	userData struct {
+		User models.User `json:"user"`
+	}
+	usersData struct {
+		Users []models.User `json:"users"`
+	}
+	changeRequestData struct {
+		ChangeRequest models.ChangeRequest `json:"changeRequest"`
+	}
+	changeRequestsData struct {
+		ChangeRequests []models.ChangeRequest `json:"changeRequests"`
+	}
+	duplicateReportData struct {
+		Clusters []models.DuplicateCluster `json:"clusters"`
+		Total    int                       `json:"total"`
+	}
+	mergeData struct {
+		Anime         models.Anime `json:"anime"`
+		MergedIDs     []string     `json:"mergedIds"`
+		MovedEpisodes int64        `json:"movedEpisodes"`
+	}
+	historyData struct {
+		History []models.AnimeHistoryEntry `json:"history"`
+	}
+	importData struct {
+		Summary importer.Summary `json:"summary"`
+	}
+	episodeData struct {
+		Episode models.Episode `json:"episode"`
+	}
+	latestEpisodesData struct {
+		Cards      []models.LatestEpisodesCard `json:"cards"`
+		NextCursor *string                     `json:"nextCursor"` // null on the last page
+	}
+	episodeNavigationData struct {
+		Episode    models.Episode           `json:"episode"`
+		Navigation models.EpisodeNavigation `json:"navigation"`
+	}
+	downloadData struct {
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	sourcesData struct {
+		Sources []models.VideoSource `json:"sources"`
+	}
+	markersData struct {
+		Markers []models.SkipMarker `json:"markers"`
+	}
+	markerSubmissionData struct {
+		Submission models.MarkerSubmission `json:"submission"`
+	}
+	markerSubmissionsData struct {
+		Submissions []models.MarkerSubmission `json:"submissions"`
+	}
+	sourceReportData struct {
+		Report models.SourceReport `json:"report"`
+	}
+	reportedSourcesData struct {
+		Reports []models.ReportedSource `json:"reports"`
+	}
+	resolvedReportsData struct {
+		Resolved int64 `json:"resolved"`
+	}
+	libraryData struct {
+		Entries    []models.LibraryEntry `json:"entries"`
+		Total      int64                 `json:"total"`
+		Page       int                   `json:"page"`
+		Limit      int                   `json:"limit"`
+		TotalPages int                   `json:"total_pages"`
+	}
+	libraryEntryData struct {
+		Entry models.LibraryEntry `json:"entry"`
+	}
+	progressData struct {
+		Progress *models.WatchProgress `json:"progress"` // null when the heartbeat was throttled
+		Saved    bool                  `json:"saved"`
+	}
+	progressListData struct {
+		Progress []models.WatchProgress `json:"progress"`
+	}
+	continueWatchingData struct {
+		Items []models.ContinueWatchingItem `json:"items"`
+	}
+	ratingData struct {
+		Rating models.Rating `json:"rating"`
+	}
+	reviewData struct {
+		Review models.Review `json:"review"`
+	}
+	reviewsData struct {
+		Reviews    []models.Review `json:"reviews"`
+		Total      int64           `json:"total"`
+		Page       int             `json:"page"`
+		Limit      int             `json:"limit"`
+		TotalPages int             `json:"total_pages"`
+	}
+	commentData struct {
+		Comment models.Comment `json:"comment"`
+	}
+	commentsData struct {
+		Comments   []models.Comment `json:"comments"`
+		Total      int64            `json:"total"`
+		Page       int              `json:"page"`
+		Limit      int              `json:"limit"`
+		TotalPages int              `json:"total_pages"`
+	}
+	followData struct {
+		Follow models.Follow `json:"follow"`
+	}
+	followStatusData struct {
+		Following bool `json:"following"`
+	}
+	followsData struct {
+		Follows []models.Follow `json:"follows"`
+	}
+	notificationData struct {
+		Notification models.Notification `json:"notification"`
+	}
+	notificationsData struct {
+		Notifications []models.Notification `json:"notifications"`
+		Unread        int64                 `json:"unread"`
+		Total         int64                 `json:"total"`
+		Page          int                   `json:"page"`
+		Limit         int                   `json:"limit"`
+		TotalPages    int                   `json:"total_pages"`
+	}
+	readAllData struct {
+		Updated int64 `json:"updated"`
+	}
+	subtitleData struct {
+		Subtitle models.SubtitleTrack `json:"subtitle"`
+	}
+	metaData struct {
+		Meta models.SeoMeta `json:"meta"`
+	}
+	uploadData struct {
+		URL string `json:"url"`
+		Key string `json:"key"`
+	}
+	deleteCoverResponse struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Key     string `json:"key"`
+	}
+	fixImageURLsResponse struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Updated int    `json:"updated"`
+	}
+	graphQLResponse struct {
+		Data   interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors,omitempty"`
+	}
+	healthResponse struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+)
+
+// apiDocs lists an operation for every route registered in SetupRoutes.
+// TestEveryRouteIsDocumented fails when the two drift apart.
+var apiDocs = []openapi.Operation{
+	// Auth
+	{Method: fiber.MethodPost, Path: "/api/auth/register", Summary: "Register a new user account", Tags: []string{"auth"},
+		Request: models.RegisterRequest{}, Body: models.LoginResponse{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPost, Path: "/api/auth/login", Summary: "Log in and receive a JWT", Tags: []string{"auth"},
+		Request: models.LoginRequest{}, Body: models.LoginResponse{}},
+	{Method: fiber.MethodGet, Path: "/api/auth/me", Summary: "Get the current user", Tags: []string{"auth"}, Auth: true,
+		Body: models.AuthResponse{}},
+
+	// Personal library
+	{Method: fiber.MethodGet, Path: "/api/me/library", Summary: "List the current user's library, most recently updated first", Tags: []string{"library"}, Auth: true,
+		Query: []openapi.Param{{Name: "status", Description: "watching, completed, on_hold, dropped or plan_to_watch"}, {Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 30, max 100"}},
+		Data:  libraryData{}},
+	{Method: fiber.MethodGet, Path: "/api/me/library/counts", Summary: "Count the current user's library entries per watch status", Tags: []string{"library"}, Auth: true,
+		Data: models.LibraryCounts{}},
+	{Method: fiber.MethodGet, Path: "/api/me/library/:animeId", Summary: "Get the current user's library entry for an anime", Tags: []string{"library"}, Auth: true,
+		Data: libraryEntryData{}},
+	{Method: fiber.MethodPut, Path: "/api/me/library/:animeId", Summary: "Add an anime to the current user's library or replace its entry", Tags: []string{"library"}, Auth: true,
+		Request: models.LibraryEntryRequest{}, Data: libraryEntryData{}},
+	{Method: fiber.MethodDelete, Path: "/api/me/library/:animeId", Summary: "Remove an anime from the current user's library", Tags: []string{"library"}, Auth: true},
+	{Method: fiber.MethodGet, Path: "/api/me/continue-watching", Summary: "Get the episode to play next for each anime the current user is watching", Tags: []string{"library"}, Auth: true,
+		Query: []openapi.Param{{Name: "limit", Description: "Number of anime, default 20, max 50"}},
+		Data:  continueWatchingData{}},
+	{Method: fiber.MethodGet, Path: "/api/me/progress", Summary: "Get the current user's playback progress in the episodes of an anime", Tags: []string{"library"}, Auth: true,
+		Query: []openapi.Param{{Name: "animeId", Required: true}},
+		Data:  progressListData{}},
+
+	// Follows and notifications
+	{Method: fiber.MethodGet, Path: "/api/me/follows", Summary: "List the anime the current user follows, most recent first", Tags: []string{"notifications"}, Auth: true,
+		Data: followsData{}},
+	{Method: fiber.MethodGet, Path: "/api/me/notifications", Summary: "List the current user's notifications, newest first", Tags: []string{"notifications"}, Auth: true,
+		Query: []openapi.Param{{Name: "unread", Description: "true to list only unread notifications"}, {Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 30, max 100"}},
+		Data:  notificationsData{}},
+	{Method: fiber.MethodPost, Path: "/api/me/notifications/read-all", Summary: "Mark all of the current user's notifications read", Tags: []string{"notifications"}, Auth: true,
+		Data: readAllData{}},
+	{Method: fiber.MethodPost, Path: "/api/me/notifications/:id/read", Summary: "Mark a notification read", Tags: []string{"notifications"}, Auth: true,
+		Data: notificationData{}},
+	{Method: fiber.MethodDelete, Path: "/api/me/notifications/:id", Summary: "Delete a notification", Tags: []string{"notifications"}, Auth: true},
+
+	// Anime
+	{Method: fiber.MethodGet, Path: "/api/anime", Summary: "List anime", Tags: []string{"anime"},
+		Query: []openapi.Param{{Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 30"}},
+		Body:  models.AnimeListResponse{}},
+	{Method: fiber.MethodGet, Path: "/api/anime/slug/:slug", Summary: "Get an anime by slug, redirecting retired slugs", Tags: []string{"anime"},
+		Data: animeData{}},
+	{Method: fiber.MethodGet, Path: "/api/anime/:id", Summary: "Get an anime by ID", Tags: []string{"anime"},
+		Data: animeData{}},
+	{Method: fiber.MethodGet, Path: "/api/anime/:id/meta", Summary: "Get SEO metadata for an anime page", Tags: []string{"anime"},
+		Data: metaData{}},
+	{Method: fiber.MethodPost, Path: "/api/anime", Summary: "Create an anime (409 with candidates on likely duplicates, 202 in review mode)", Tags: []string{"anime"}, Auth: true,
+		Query:   []openapi.Param{{Name: "force", Description: "Set to true to skip the duplicate check"}},
+		Request: models.AnimeRequest{}, Data: animeData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/api/anime/:id", Summary: "Update an anime (202 in review mode)", Tags: []string{"anime"}, Auth: true,
+		Request: models.AnimeRequest{}},
+	{Method: fiber.MethodDelete, Path: "/api/anime/:id", Summary: "Delete an anime (202 in review mode)", Tags: []string{"anime"}, Auth: true},
+
+	// Ratings and reviews
+	{Method: fiber.MethodGet, Path: "/api/anime/top", Summary: "List rated anime by weighted (Bayesian) rating", Tags: []string{"reviews"},
+		Query: []openapi.Param{{Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 30, max 100"}},
+		Body:  models.AnimeListResponse{}},
+	{Method: fiber.MethodGet, Path: "/api/anime/:id/rating", Summary: "Get the current user's rating of an anime", Tags: []string{"reviews"}, Auth: true,
+		Data: ratingData{}},
+	{Method: fiber.MethodPut, Path: "/api/anime/:id/rating", Summary: "Rate an anime from 1 to 10", Tags: []string{"reviews"}, Auth: true,
+		Request: models.RatingRequest{}, Data: ratingData{}},
+	{Method: fiber.MethodDelete, Path: "/api/anime/:id/rating", Summary: "Delete the current user's rating of an anime", Tags: []string{"reviews"}, Auth: true},
+	{Method: fiber.MethodGet, Path: "/api/anime/:id/reviews", Summary: "List the reviews of an anime", Tags: []string{"reviews"},
+		Query: []openapi.Param{{Name: "sort", Description: "helpful (default) or recent"}, {Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 20, max 50"}},
+		Data:  reviewsData{}},
+	{Method: fiber.MethodPost, Path: "/api/anime/:id/reviews", Summary: "Review an anime; the review score also rates it", Tags: []string{"reviews"}, Auth: true,
+		Request: models.AnimeReviewRequest{}, Data: reviewData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodGet, Path: "/api/anime/:id/follow", Summary: "Check whether the current user follows an anime", Tags: []string{"notifications"}, Auth: true,
+		Data: followStatusData{}},
+	{Method: fiber.MethodPut, Path: "/api/anime/:id/follow", Summary: "Follow an anime to be notified of new episodes, sources and status changes", Tags: []string{"notifications"}, Auth: true,
+		Data: followData{}},
+	{Method: fiber.MethodDelete, Path: "/api/anime/:id/follow", Summary: "Unfollow an anime", Tags: []string{"notifications"}, Auth: true},
+	{Method: fiber.MethodPut, Path: "/api/reviews/:id", Summary: "Edit the current user's review within REVIEW_EDIT_WINDOW of writing it", Tags: []string{"reviews"}, Auth: true,
+		Request: models.AnimeReviewRequest{}, Data: reviewData{}},
+	{Method: fiber.MethodDelete, Path: "/api/reviews/:id", Summary: "Delete the current user's review", Tags: []string{"reviews"}, Auth: true},
+	{Method: fiber.MethodPut, Path: "/api/reviews/:id/helpful", Summary: "Mark a review helpful", Tags: []string{"reviews"}, Auth: true,
+		Data: reviewData{}},
+	{Method: fiber.MethodDelete, Path: "/api/reviews/:id/helpful", Summary: "Withdraw a helpful vote on a review", Tags: []string{"reviews"}, Auth: true,
+		Data: reviewData{}},
+	{Method: fiber.MethodPost, Path: "/api/reviews/:id/remove", Summary: "Remove a review as a moderator (requires moderate_reviews)", Tags: []string{"reviews"}, Auth: true,
+		Request: models.RemoveReviewRequest{}, Data: reviewData{}},
+
+	// Comments; new comments are also sent to websocket clients subscribed to
+	// the room of their page, "anime:<id>" or "episode:<id>"
+	{Method: fiber.MethodGet, Path: "/api/anime/:id/comments", Summary: "List the comments on an anime page, pinned first", Tags: []string{"comments"},
+		Query: []openapi.Param{{Name: "sort", Description: "top (default) or newest"}, {Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 20, max 50"}},
+		Data:  commentsData{}},
+	{Method: fiber.MethodPost, Path: "/api/anime/:id/comments", Summary: "Comment on an anime page", Tags: []string{"comments"}, Auth: true,
+		Request: models.CommentRequest{}, Data: commentData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodGet, Path: "/api/episodes/:id/comments", Summary: "List the comments on an episode page, pinned first", Tags: []string{"comments"}, Auth: true,
+		Query: []openapi.Param{{Name: "sort", Description: "top (default) or newest"}, {Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 20, max 50"}},
+		Data:  commentsData{}},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/comments", Summary: "Comment on an episode page", Tags: []string{"comments"}, Auth: true,
+		Request: models.CommentRequest{}, Data: commentData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodGet, Path: "/api/comments/:id/replies", Summary: "List the replies to a comment, oldest first", Tags: []string{"comments"},
+		Query: []openapi.Param{{Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 20, max 50"}},
+		Data:  commentsData{}},
+	{Method: fiber.MethodPost, Path: "/api/comments/:id/replies", Summary: "Reply to a comment; replies nest two levels deep and locked threads take none", Tags: []string{"comments"}, Auth: true,
+		Request: models.CommentRequest{}, Data: commentData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/api/comments/:id", Summary: "Edit the current user's comment", Tags: []string{"comments"}, Auth: true,
+		Request: models.CommentRequest{}, Data: commentData{}},
+	{Method: fiber.MethodDelete, Path: "/api/comments/:id", Summary: "Delete the current user's comment; a comment with replies is blanked instead", Tags: []string{"comments"}, Auth: true},
+	{Method: fiber.MethodPut, Path: "/api/comments/:id/reactions/:reaction", Summary: "React to a comment: like, love, laugh, wow, sad or angry", Tags: []string{"comments"}, Auth: true,
+		Data: commentData{}},
+	{Method: fiber.MethodDelete, Path: "/api/comments/:id/reactions/:reaction", Summary: "Withdraw a reaction to a comment", Tags: []string{"comments"}, Auth: true,
+		Data: commentData{}},
+	{Method: fiber.MethodPost, Path: "/api/comments/:id/moderate", Summary: "Hide, lock or pin a comment as a moderator (requires moderate_comments)", Tags: []string{"comments"}, Auth: true,
+		Request: models.ModerateCommentRequest{}, Data: commentData{}},
+
+	// Admin anime maintenance
+	{Method: fiber.MethodGet, Path: "/api/admin/anime/duplicates", Summary: "Report clusters of likely duplicate anime", Tags: []string{"admin"}, Auth: true,
+		Data: duplicateReportData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/anime/merge", Summary: "Merge duplicate anime into a survivor; fails with 409 when their episode numbers collide", Tags: []string{"admin"}, Auth: true,
+		Request: models.MergeAnimeRequest{}, Data: mergeData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/anime/import", Summary: "Import anime metadata from a dump file (MAL XML exports only enrich existing anime)", Tags: []string{"admin"}, Auth: true,
+		Request: models.ImportRequest{}, Data: importData{}},
+	{Method: fiber.MethodGet, Path: "/api/admin/anime/:id/history", Summary: "Get the edit history of an anime", Tags: []string{"admin"}, Auth: true,
+		Data: historyData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/fix-image-urls", Summary: "Rewrite localhost cover URLs to the configured base URL", Tags: []string{"admin"}, Auth: true,
+		Body: fixImageURLsResponse{}},
+
+	// Editorial review
+	{Method: fiber.MethodGet, Path: "/api/admin/change-requests", Summary: "List change requests", Tags: []string{"review"}, Auth: true,
+		Query: []openapi.Param{{Name: "status", Description: "pending (default), approved, rejected or all"}},
+		Data:  changeRequestsData{}},
+	{Method: fiber.MethodGet, Path: "/api/admin/change-requests/:id", Summary: "Get a change request", Tags: []string{"review"}, Auth: true,
+		Data: changeRequestData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/change-requests/:id/approve", Summary: "Approve and apply a change request (409 with candidates when a created anime is a likely duplicate)", Tags: []string{"review"}, Auth: true,
+		Query:   []openapi.Param{{Name: "force", Description: "Set to true to skip the duplicate check"}},
+		Request: models.ReviewRequest{}, Data: changeRequestData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/change-requests/:id/reject", Summary: "Reject a change request", Tags: []string{"review"}, Auth: true,
+		Request: models.ReviewRequest{}, Data: changeRequestData{}},
+
+	// Source report moderation
+	{Method: fiber.MethodGet, Path: "/api/admin/source-reports", Summary: "List reported video sources with open reports, most reported first", Tags: []string{"review"}, Auth: true,
+		Query: []openapi.Param{{Name: "page", Description: "Page number, default 1"}, {Name: "limit", Description: "Page size, default 50, max 100"}},
+		Data:  reportedSourcesData{}},
+	{Method: fiber.MethodPost, Path: "/api/admin/source-reports/:episodeId/:sourceId/resolve", Summary: "Resolve the open reports of a video source", Tags: []string{"review"}, Auth: true,
+		Data: resolvedReportsData{}},
+
+	// Users
+	{Method: fiber.MethodGet, Path: "/api/users", Summary: "List users", Tags: []string{"users"}, Auth: true,
+		Data: usersData{}},
+	{Method: fiber.MethodGet, Path: "/api/users/:id", Summary: "Get a user", Tags: []string{"users"}, Auth: true,
+		Data: userData{}},
+	{Method: fiber.MethodPost, Path: "/api/users", Summary: "Create a user", Tags: []string{"users"}, Auth: true,
+		Request: models.CreateUserRequest{}, Data: userData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/api/users/:id/role", Summary: "Change a user's role", Tags: []string{"users"}, Auth: true,
+		Request: models.UpdateRoleRequest{}},
+	{Method: fiber.MethodDelete, Path: "/api/users/:id", Summary: "Delete a user", Tags: []string{"users"}, Auth: true},
+
+	// Uploads
+	{Method: fiber.MethodGet, Path: "/api/upload/image/*", Summary: "Serve an uploaded cover image (covers/ keys only)", Tags: []string{"upload"},
+		ContentType: "image/*"},
+	{Method: fiber.MethodGet, Path: "/api/upload/file/*", Summary: "Serve an uploaded subtitle track (subtitles/ keys only)", Tags: []string{"upload"},
+		ContentType: "application/octet-stream"},
+	{Method: fiber.MethodPost, Path: "/api/upload/cover", Summary: "Upload a cover image", Tags: []string{"upload"}, Auth: true,
+		Files: []string{"file"}, Data: uploadData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodDelete, Path: "/api/upload/cover", Summary: "Delete a cover image", Tags: []string{"upload"}, Auth: true,
+		Request: models.DeleteCoverRequest{}, Body: deleteCoverResponse{}},
+
+	// Episodes
+	{Method: fiber.MethodGet, Path: "/api/episodes", Summary: "List episodes of an anime visible to the caller (released, or past vipAt for early access roles; editors see all): regular before specials, then by season and number (paginated as EpisodeListResponse when page or limit is set)", Tags: []string{"episodes"}, Auth: true,
+		Query: []openapi.Param{
+			{Name: "animeId", Description: "Anime ID", Required: true},
+			{Name: "season", Description: "Season number"},
+			{Name: "type", Description: "regular or special"},
+			{Name: "page", Description: "Page number, default 1"},
+			{Name: "limit", Description: "Page size, default 50, at most 100"},
+		},
+		Data: []models.Episode{}},
+	{Method: fiber.MethodGet, Path: "/api/episodes/latest", Summary: "Newest episodes across the catalog, with episodes of one anime released together collapsed into one card", Tags: []string{"episodes"},
+		Query: []openapi.Param{
+			{Name: "cursor", Description: "nextCursor of the previous page"},
+			{Name: "limit", Description: "Cards per page, default 20, at most 50"},
+		},
+		Data: latestEpisodesData{}},
+	{Method: fiber.MethodGet, Path: "/api/episodes/:id", Summary: "Get an episode with previous/next navigation; unreleased episodes are not found unless the caller has early access", Tags: []string{"episodes"}, Auth: true,
+		Data: episodeNavigationData{}},
+	{Method: fiber.MethodPost, Path: "/api/episodes", Summary: "Create an episode of an existing anime, released at publicAt (default now) and to early access roles at vipAt", Tags: []string{"episodes"}, Auth: true,
+		Request: models.EpisodeRequest{}, Data: episodeData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/api/episodes/:id", Summary: "Update an episode", Tags: []string{"episodes"}, Auth: true,
+		Request: models.EpisodeRequest{}, Data: episodeData{}},
+	{Method: fiber.MethodDelete, Path: "/api/episodes/:id", Summary: "Delete an episode", Tags: []string{"episodes"}, Auth: true},
+	{Method: fiber.MethodPut, Path: "/api/episodes/:id/sources", Summary: "Replace the video sources of an episode", Tags: []string{"episodes"}, Auth: true,
+		Request: models.SourcesRequest{}, Data: sourcesData{}},
+	{Method: fiber.MethodPut, Path: "/api/episodes/:id/sources/order", Summary: "Reorder the video sources of an episode", Tags: []string{"episodes"}, Auth: true,
+		Request: models.ReorderSourcesRequest{}, Data: sourcesData{}},
+	{Method: fiber.MethodPut, Path: "/api/episodes/:id/sources/:sourceId/status", Summary: "Enable or disable a video source", Tags: []string{"episodes"}, Auth: true,
+		Request: models.SourceStatusRequest{}, Data: sourcesData{}},
+	{Method: fiber.MethodGet, Path: "/api/episodes/:id/sources/:sourceId/download", Summary: "Get a signed, expiring download URL for a hosted MP4 source (requires download_episodes, e.g. vip)", Tags: []string{"episodes"}, Auth: true,
+		Data: downloadData{}},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/sources/:sourceId/reports", Summary: "Report a broken video source; it is disabled once it has SOURCE_REPORT_THRESHOLD open reports", Tags: []string{"episodes"}, Auth: true,
+		Request: models.SourceReportRequest{}, Data: sourceReportData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/progress", Summary: "Playback heartbeat: store the current user's position; writes are throttled to one per 10 seconds per episode", Tags: []string{"episodes"}, Auth: true,
+		Request: models.ProgressRequest{}, Data: progressData{}},
+	{Method: fiber.MethodPut, Path: "/api/episodes/:id/markers", Summary: "Replace the skip markers (opening, ending, recap, preview) of an episode", Tags: []string{"episodes"}, Auth: true,
+		Request: models.MarkersRequest{}, Data: markersData{}},
+	{Method: fiber.MethodGet, Path: "/api/episodes/:id/markers/submissions", Summary: "List pending skip marker submissions of an episode", Tags: []string{"episodes"}, Auth: true,
+		Data: markerSubmissionsData{}},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/markers/submissions", Summary: "Submit a skip marker; it is added to the episode once its vote score reaches MARKER_VOTE_THRESHOLD", Tags: []string{"episodes"}, Auth: true,
+		Request: models.SkipMarker{}, Data: markerSubmissionData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/markers/submissions/:submissionId/vote", Summary: "Confirm (1) or dispute (-1) a skip marker submission", Tags: []string{"episodes"}, Auth: true,
+		Request: models.MarkerVoteRequest{}, Data: markerSubmissionData{}},
+	{Method: fiber.MethodPost, Path: "/api/episodes/:id/subtitles", Summary: "Upload an SRT, ASS/SSA or WebVTT subtitle track (form fields: language, label, default)", Tags: []string{"episodes"}, Auth: true,
+		Files: []string{"file"}, Data: subtitleData{}, Status: fiber.StatusCreated},
+	{Method: fiber.MethodDelete, Path: "/api/episodes/:id/subtitles/:trackId", Summary: "Delete a subtitle track", Tags: []string{"episodes"}, Auth: true},
+
+	// Slider
+	{Method: fiber.MethodGet, Path: "/api/slider", Summary: "List slider items", Tags: []string{"slider"},
+		Data: []controllers.SliderItem{}},
+	{Method: fiber.MethodPut, Path: "/api/slider", Summary: "Replace all slider items", Tags: []string{"slider"}, Auth: true,
+		Request: []controllers.SliderItem{}, Data: []controllers.SliderItem{}},
+
+	// Documentation
+	{Method: fiber.MethodGet, Path: "/api/openapi.json", Summary: "OpenAPI specification", Tags: []string{"docs"},
+		Body: map[string]interface{}{}},
+	{Method: fiber.MethodGet, Path: "/api/docs", Summary: "Interactive API documentation", Tags: []string{"docs"},
+		ContentType: fiber.MIMETextHTML},
+
+	// GraphQL
+	{Method: fiber.MethodGet, Path: "/graphql", Summary: "Run a GraphQL query", Tags: []string{"graphql"},
+		Query: []openapi.Param{{Name: "query", Required: true}, {Name: "variables", Description: "JSON encoded"}, {Name: "operationName"}},
+		Body:  graphQLResponse{}},
+	{Method: fiber.MethodPost, Path: "/graphql", Summary: "Run a GraphQL query or mutation", Tags: []string{"graphql"},
+		Request: models.GraphQLRequest{}, Body: graphQLResponse{}},
+
+	// Sitemap and feeds
+	{Method: fiber.MethodGet, Path: "/sitemap.xml", Summary: "Sitemap, or sitemap index for large catalogs", Tags: []string{"feeds"},
+		ContentType: fiber.MIMEApplicationXML},
+	{Method: fiber.MethodGet, Path: "/sitemaps/anime-:page.xml", Summary: "Sitemap page", Tags: []string{"feeds"},
+		ContentType: fiber.MIMEApplicationXML},
+	{Method: fiber.MethodGet, Path: "/feeds/anime.:format", Summary: "Newly added anime as RSS (rss) or Atom (atom)", Tags: []string{"feeds"},
+		ContentType: fiber.MIMEApplicationXML},
+	{Method: fiber.MethodGet, Path: "/feeds/episodes.:format", Summary: "Newly added episodes as RSS (rss) or Atom (atom)", Tags: []string{"feeds"},
+		ContentType: fiber.MIMEApplicationXML},
+	{Method: fiber.MethodGet, Path: "/feeds/anime/:slug/episodes.:format", Summary: "Newly added episodes of one anime as RSS (rss) or Atom (atom)", Tags: []string{"feeds"},
+		ContentType: fiber.MIMEApplicationXML},
+
+	// Media
+	{Method: fiber.MethodGet, Path: "/media/*", Summary: "Stream an episode video, HLS segment or download through a signed URL from the episode or download endpoints; supports Range and conditional requests. HLS playlists (.m3u8) are returned with their URIs rewritten to signed URLs", Tags: []string{"media"},
+		Query: []openapi.Param{
+			{Name: "exp", Description: "Expiry, Unix seconds", Required: true},
+			{Name: "uid", Description: "User the URL was signed for", Required: true},
+			{Name: "dl", Description: "1 for downloads"},
+			{Name: "sig", Description: "HMAC-SHA256 signature", Required: true},
+		},
+		ContentType: "application/octet-stream"},
+
+	// Realtime and health
+	{Method: fiber.MethodGet, Path: "/ws", Summary: "WebSocket for realtime user updates; with a token it also receives notifications and accepts progress messages ({\"type\": \"progress\", \"episodeId\", \"position\", \"duration\"})", Tags: []string{"realtime"},
+		Query: []openapi.Param{{Name: "userID", Description: "Required without token"}, {Name: "token", Description: "JWT access token"}}, Status: fiber.StatusSwitchingProtocols},
+	{Method: fiber.MethodGet, Path: "/health", Summary: "Health check", Tags: []string{"health"},
+		Body: healthResponse{}},
+}
+
+var apiSpec = openapi.New(openapi.Info{
+	Title:       "Toofy API",
+	Version:     "2.0.0",
+	Description: "Anime catalog backend. /api/v2 responses use models.Envelope with the resource directly in data; the unversioned /api routes are deprecated.",
+}, versionedDocs(apiDocs))
+
+// versionedDocs marks the unversioned /api operations as deprecated and adds
+// their /api/v2 counterparts with the envelope shapes the handlers respond with
+func versionedDocs(docs []openapi.Operation) []openapi.Operation {
+	all := []openapi.Operation{}
+	v2 := []openapi.Operation{}
+	for _, op := range docs {
+		if !strings.HasPrefix(op.Path, "/api/") || op.Path == "/api/openapi.json" || op.Path == "/api/docs" {
+			all = append(all, op)
+			continue
+		}
+
+		v2Op := op
+		v2Op.Path = "/api/v2" + strings.TrimPrefix(op.Path, "/api")
+		v2Op.Data, v2Op.Meta = v2Data(op)
+		v2Op.Body = nil
+		v2 = append(v2, v2Op)
+
+		op.Deprecated = true
+		all = append(all, op)
+	}
+	return append(all, v2...)
+}
+
+// v2Data returns the data and meta values of an operation's v2 response
+func v2Data(op openapi.Operation) (data interface{}, meta interface{}) {
+	if op.Data != nil {
+		if data, ok := pageData(reflect.TypeOf(op.Data)); ok {
+			return data, models.PageMeta{}
+		}
+		return unwrapSingleField(op.Data), nil
+	}
+	if op.Body == nil {
+		return nil, nil
+	}
+
+	body := reflect.TypeOf(op.Body)
+	if field, ok := body.FieldByName("Data"); ok {
+		// Paginated lists move their paging fields to meta
+		if field.Type.Kind() == reflect.Struct {
+			if items, ok := field.Type.FieldByName("Data"); ok {
+				return reflect.Zero(items.Type).Interface(), models.PageMeta{}
+			}
+		}
+		return unwrapSingleField(reflect.Zero(field.Type).Interface()), nil
+	}
+
+	// Loose top-level fields move into data
+	fields := []reflect.StructField{}
+	for i := 0; i < body.NumField(); i++ {
+		if name := body.Field(i).Name; name != "Success" && name != "Message" {
+			fields = append(fields, body.Field(i))
+		}
+	}
+	return reflect.Zero(reflect.StructOf(fields)).Interface(), nil
+}
+
+// pageData returns the v2 data of a paginated shape, one with a total_pages
+// field, whose paging fields move to meta: the item list alone, or the
+// remaining fields when the page carries more, such as an unread count
+func pageData(t reflect.Type) (interface{}, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	paginated := false
+	fields := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		switch field.Tag.Get("json") {
+		case "total_pages":
+			paginated = true
+		case "total", "page", "limit":
+		default:
+			fields = append(fields, field)
+		}
+	}
+	switch {
+	case !paginated || len(fields) == 0:
+		return nil, false
+	case len(fields) == 1:
+		return reflect.Zero(fields[0].Type).Interface(), true
+	}
+	return reflect.Zero(reflect.StructOf(fields)).Interface(), true
+}
+
+// unwrapSingleField returns the field value of a struct with exactly one
+// field, which v2 puts in data in place of the wrapping object
+func unwrapSingleField(value interface{}) interface{} {
+	t := reflect.TypeOf(value)
+	if t.Kind() != reflect.Struct || t.NumField() != 1 {
+		return value
+	}
+	return reflect.Zero(t.Field(0).Type).Interface()
+}
+
+// docsHandlers serves the OpenAPI document, built on first request from the
+// routes registered on the app, and a Swagger UI page that renders it
+func docsHandlers(app *fiber.App) (spec fiber.Handler, ui fiber.Handler) {
+	var (
+		once     sync.Once
+		document map[string]interface{}
+	)
+
+	spec = func(c *fiber.Ctx) error {
+		once.Do(func() {
+			document = apiSpec.Document(app.GetRoutes(true))
+		})
+		return c.JSON(document)
+	}
+
+	ui = func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(swaggerUIPage)
+	}
+
+	return spec, ui
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Toofy API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,281 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+	"toofy-backend/database"
+	"toofy-backend/openapi"
+	"toofy-backend/utils"
+)
+
+// TestDocumentedResponses runs handlers against canned database replies and
+// checks that their responses have exactly the documented shape, so the
+// response types in docs.go cannot drift from what the handlers send.
+func TestDocumentedResponses(t *testing.T) {
+	animeID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	anime := bson.D{
+		{Key: "_id", Value: animeID},
+		{Key: "title", Value: "Frieren"},
+		{Key: "slug", Value: "frieren"},
+		{Key: "episodeCount", Value: 28},
+		{Key: "createdAt", Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	notification := bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "userId", Value: userID.Hex()},
+		{Key: "type", Value: "episode_released"},
+		{Key: "animeId", Value: animeID.Hex()},
+		{Key: "read", Value: false},
+		{Key: "createdAt", Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	token, err := utils.GenerateToken(userID, "user@example.com", "user", "test-secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		docPath   string // path of the documented operation
+		path      string
+		token     string
+		responses []bson.D // database replies, in the order the handler queries
+	}{
+		{
+			name:      "anime by id",
+			docPath:   "/api/anime/:id",
+			path:      "/api/anime/" + animeID.Hex(),
+			responses: []bson.D{cursorReply("anime", anime)},
+		},
+		{
+			name:      "anime list",
+			docPath:   "/api/anime",
+			path:      "/api/anime?page=2&limit=1",
+			responses: []bson.D{cursorReply("anime", bson.D{{Key: "n", Value: 3}}), cursorReply("anime", anime)},
+		},
+		{
+			name:    "notifications",
+			docPath: "/api/me/notifications",
+			path:    "/api/me/notifications",
+			token:   token,
+			responses: []bson.D{
+				cursorReply("notifications", bson.D{{Key: "n", Value: 1}}),
+				cursorReply("notifications", bson.D{{Key: "n", Value: 1}}),
+				cursorReply("notifications", notification),
+			},
+		},
+	}
+
+	ops := versionedDocs(apiDocs)
+	app := newTestApp()
+	for _, tt := range tests {
+		for _, version := range []string{"/api", "/api/v2"} {
+			docPath := version + tt.docPath[len("/api"):]
+			path := version + tt.path[len("/api"):]
+
+			t.Run(tt.name+" "+version, func(t *testing.T) {
+				op := findOperation(ops, fiber.MethodGet, docPath)
+				if op == nil {
+					t.Fatalf("no documented operation GET %s", docPath)
+				}
+				useMockDB(t, tt.responses...)
+
+				req := httptest.NewRequest(fiber.MethodGet, path, nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				if resp.StatusCode != fiber.StatusOK {
+					t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+				}
+
+				if op.Body != nil {
+					checkDocumented(t, "body", body, op.Body)
+					return
+				}
+
+				var envelope map[string]json.RawMessage
+				if err := json.Unmarshal(body, &envelope); err != nil {
+					t.Fatal(err)
+				}
+				for key := range envelope {
+					if key != "success" && key != "message" && key != "data" && key != "meta" {
+						t.Errorf("undocumented top-level field %q", key)
+					}
+				}
+				checkDocumented(t, "data", envelope["data"], op.Data)
+				if op.Meta != nil {
+					checkDocumented(t, "meta", envelope["meta"], op.Meta)
+				} else if _, ok := envelope["meta"]; ok {
+					t.Errorf("meta present but not documented")
+				}
+			})
+		}
+	}
+}
+
+func findOperation(ops []openapi.Operation, method, path string) *openapi.Operation {
+	for i := range ops {
+		if ops[i].Method == method && ops[i].Path == path {
+			return &ops[i]
+		}
+	}
+	return nil
+}
+
+// checkDocumented decodes raw into the documented type, rejecting unknown
+// fields, and checks that encoding it again gives the same JSON, so no
+// documented field is missing either
+func checkDocumented(t *testing.T, name string, raw []byte, doc interface{}) {
+	t.Helper()
+	if len(raw) == 0 {
+		t.Errorf("%s missing", name)
+		return
+	}
+
+	value := reflect.New(reflect.TypeOf(doc))
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value.Interface()); err != nil {
+		t.Errorf("%s does not match %T: %v\n%s", name, doc, err, raw)
+		return
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want interface{}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(encoded, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s does not match %T\ngot:        %s\ndocumented: %s", name, doc, raw, encoded)
+	}
+}
+
+// cursorReply is the reply to a find or aggregate returning docs in one batch
+func cursorReply(collection string, docs ...bson.D) bson.D {
+	batch := bson.A{}
+	for _, doc := range docs {
+		batch = append(batch, doc)
+	}
+	return bson.D{
+		{Key: "ok", Value: 1},
+		{Key: "cursor", Value: bson.D{
+			{Key: "id", Value: int64(0)},
+			{Key: "ns", Value: "test." + collection},
+			{Key: "firstBatch", Value: batch},
+		}},
+	}
+}
+
+// useMockDB points database.DB at a deployment that answers each command
+// with the next of the given replies
+func useMockDB(t *testing.T, replies ...bson.D) {
+	t.Helper()
+	deployment := &mockDeployment{conn: &mockConnection{replies: replies}}
+	client, err := mongo.Connect(context.Background(), &options.ClientOptions{Deployment: deployment})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = client.Database("test")
+	t.Cleanup(func() {
+		database.DB = previous
+		if left := len(deployment.conn.replies); left > 0 {
+			t.Errorf("%d database replies left unused", left)
+		}
+	})
+}
+
+var mockServer = description.Server{
+	CanonicalAddr:         address.Address("localhost:27017"),
+	MaxDocumentSize:       16777216,
+	MaxMessageSize:        48000000,
+	MaxBatchCount:         100000,
+	SessionTimeoutMinutes: 30,
+	Kind:                  description.RSPrimary,
+	WireVersion:           &description.VersionRange{Max: topology.SupportedWireVersions.Max},
+}
+
+// mockConnection replies to every command with the next canned reply
+type mockConnection struct {
+	replies []bson.D
+}
+
+func (c *mockConnection) WriteWireMessage(context.Context, []byte) error { return nil }
+
+func (c *mockConnection) ReadWireMessage(_ context.Context, dst []byte) ([]byte, error) {
+	if len(c.replies) == 0 {
+		return dst, errors.New("no database replies left")
+	}
+	reply, err := bson.Marshal(c.replies[0])
+	if err != nil {
+		return dst, err
+	}
+	c.replies = c.replies[1:]
+
+	var index int32
+	index, dst = wiremessage.AppendHeaderStart(dst, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, reply...)
+	return bsoncore.UpdateLength(dst, index, int32(len(dst[index:]))), nil
+}
+
+func (c *mockConnection) Description() description.Server { return mockServer }
+func (c *mockConnection) Close() error                    { return nil }
+func (c *mockConnection) ID() string                      { return "mock" }
+func (c *mockConnection) ServerConnectionID() *int32      { return nil }
+func (c *mockConnection) Address() address.Address        { return mockServer.CanonicalAddr }
+func (c *mockConnection) Stale() bool                     { return false }
+
+// mockDeployment is a single server that always hands out its one connection
+type mockDeployment struct {
+	conn *mockConnection
+}
+
+func (d *mockDeployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+func (d *mockDeployment) Kind() description.TopologyKind { return description.Single }
+func (d *mockDeployment) Connection(context.Context) (driver.Connection, error) {
+	return d.conn, nil
+}
+func (d *mockDeployment) MinRTT() time.Duration            { return 0 }
+func (d *mockDeployment) RTT90() time.Duration             { return 0 }
+func (d *mockDeployment) Connect() error                   { return nil }
+func (d *mockDeployment) Disconnect(context.Context) error { return nil }
+func (d *mockDeployment) Subscribe() (*driver.Subscription, error) {
+	updates := make(chan description.Topology, 1)
+	updates <- description.Topology{SessionTimeoutMinutes: 30}
+	return &driver.Subscription{Updates: updates}, nil
+}
+func (d *mockDeployment) Unsubscribe(*driver.Subscription) error { return nil }
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"toofy-backend/config"
+)
+
+func newTestApp() *fiber.App {
+	app := fiber.New()
+	SetupRoutes(app, &config.Config{
+		JWTSecret: "test-secret",
+		BaseURL:   "http://localhost:8081",
+		SiteURL:   "https://example.com",
+	})
+	return app
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	app := newTestApp()
+	routes := app.GetRoutes(true)
+
+	for _, route := range apiSpec.Undocumented(routes) {
+		t.Errorf("route %s has no entry in apiDocs", route)
+	}
+	for _, route := range apiSpec.Unregistered(routes) {
+		t.Errorf("apiDocs entry %s does not match a registered route", route)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	app := newTestApp()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/openapi.json", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var document struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		t.Fatal(err)
+	}
+	if document.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q, want 3.0.3", document.OpenAPI)
+	}
+	if _, ok := document.Paths["/api/anime/{id}"]["get"]; !ok {
+		t.Errorf("paths missing GET /api/anime/{id}")
+	}
+	if _, ok := document.Paths["/feeds/anime/{slug}/episodes.{format}"]["get"]; !ok {
+		t.Errorf("paths missing GET /feeds/anime/{slug}/episodes.{format}")
+	}
+}
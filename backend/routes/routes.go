@@ -7,54 +7,187 @@ import (
 	"toofy-backend/controllers"
 	"toofy-backend/handlers"
 	"toofy-backend/middleware"
+	"toofy-backend/models"
+	"toofy-backend/utils"
 )
 
+// apiControllers holds the controllers shared by every API version
+type apiControllers struct {
+	auth           *controllers.AuthController
+	users          *controllers.UsersController
+	anime          *controllers.AnimeController
+	episodes       *controllers.EpisodesController
+	upload         *controllers.UploadController
+	changeRequests *controllers.ChangeRequestsController
+	imports        *controllers.ImportController
+	seo            *controllers.SeoController
+	subtitles      *controllers.SubtitlesController
+	slider         *controllers.SliderController
+	sourceReports  *controllers.SourceReportsController
+	library        *controllers.LibraryController
+	progress       *controllers.ProgressController
+	ratings        *controllers.RatingsController
+	reviews        *controllers.ReviewsController
+	notifications  *controllers.NotificationsController
+	comments       *controllers.CommentsController
+}
+
 func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	// Initialize controllers
-	authCtrl := controllers.NewAuthController(cfg)
-	usersCtrl := controllers.NewUsersController()
-	animeCtrl := controllers.NewAnimeController()
-	episodesCtrl := controllers.NewEpisodesController()
 	uploadCtrl := controllers.NewUploadController(cfg)
+	ctrl := &apiControllers{
+		auth:           controllers.NewAuthController(cfg),
+		users:          controllers.NewUsersController(),
+		anime:          controllers.NewAnimeController(cfg),
+		episodes:       controllers.NewEpisodesController(cfg),
+		upload:         uploadCtrl,
+		changeRequests: controllers.NewChangeRequestsController(),
+		imports:        controllers.NewImportController(cfg),
+		seo:            controllers.NewSeoController(cfg, uploadCtrl),
+		subtitles:      controllers.NewSubtitlesController(uploadCtrl),
+		slider:         controllers.NewSliderController(),
+		sourceReports:  controllers.NewSourceReportsController(cfg),
+		library:        controllers.NewLibraryController(),
+		progress:       controllers.NewProgressController(cfg),
+		ratings:        controllers.NewRatingsController(),
+		reviews:        controllers.NewReviewsController(cfg),
+		notifications:  controllers.NewNotificationsController(),
+		comments:       controllers.NewCommentsController(),
+	}
+	mediaCtrl := controllers.NewMediaController(cfg, uploadCtrl)
+	feedsCtrl := controllers.NewFeedsController(cfg)
+	graphqlCtrl := controllers.NewGraphQLController(cfg)
+
+	// API documentation (public) - generated from the routes registered below
+	specHandler, docsUIHandler := docsHandlers(app)
+	app.Get("/api/openapi.json", specHandler)
+	app.Get("/api/docs", docsUIHandler)
+
+	// v2 MUST be registered before /api: middleware used on the /api group
+	// (deprecation headers, auth) also matches paths under /api/v2
+	setupAPIRoutes(app.Group("/api/v2", middleware.V2Envelope()), ctrl, cfg)
+
+	// Unversioned v1 routes, kept for the deployed frontend
+	setupAPIRoutes(app.Group("/api", middleware.DeprecatedV1(cfg)), ctrl, cfg)
+
+	// WebSocket routes
+	handlers.HandleMessage("progress", ctrl.progress.HandleProgressMessage)
+	app.Use("/ws", handlers.WebSocketHandler)
+	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
+		// Get user ID from query
+		userID := c.Query("userID")
+
+		// A token authenticates the connection for notifications and
+		// player messages
+		role := ""
+		authenticated := false
+		if token := c.Query("token"); token != "" {
+			claims, err := utils.VerifyToken(token, cfg.JWTSecret)
+			if err != nil {
+				c.Close()
+				return
+			}
+			userID, role, authenticated = claims.UserID, claims.Role, true
+		}
 
-	// Public routes
-	api := app.Group("/api")
+		if userID == "" {
+			c.Close()
+			return
+		}
+		
+		// Pass userID to handler
+		handlers.WebSocketUpgrade(c, userID, role, authenticated)
+	}))
+
+	// GraphQL (queries are public, mutations check permissions from the bearer token)
+	app.Get("/graphql", graphqlCtrl.HandleGraphQL)
+	app.Post("/graphql", graphqlCtrl.HandleGraphQL)
+
+	// Episode media (signed URLs only)
+	app.Get("/media/*", middleware.SignedMedia(cfg), mediaCtrl.GetMedia)
+
+	// Sitemap and feeds (public)
+	app.Get("/sitemap.xml", feedsCtrl.GetSitemap)
+	app.Get("/sitemaps/anime-:page.xml", feedsCtrl.GetSitemapPage)
+	app.Get("/feeds/anime.:format", feedsCtrl.GetAnimeFeed)
+	app.Get("/feeds/episodes.:format", feedsCtrl.GetEpisodesFeed)
+	app.Get("/feeds/anime/:slug/episodes.:format", feedsCtrl.GetAnimeEpisodesFeed)
+
+	// Health check
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status": "ok",
+			"message": "Server is running",
+		})
+	})
+}
 
+// setupAPIRoutes registers the REST API on a version group
+func setupAPIRoutes(api fiber.Router, ctrl *apiControllers, cfg *config.Config) {
 	// Auth routes (public)
 	auth := api.Group("/auth")
-	auth.Post("/register", authCtrl.Register)
-	auth.Post("/login", authCtrl.Login)
+	auth.Post("/register", ctrl.auth.Register)
+	auth.Post("/login", ctrl.auth.Login)
 
 	// Public anime routes (read-only)
 	publicAnime := api.Group("/anime")
-	publicAnime.Get("", animeCtrl.GetAllAnime)
-	publicAnime.Get("/:id", animeCtrl.GetAnimeByID)
+	publicAnime.Get("", ctrl.anime.GetAllAnime)
+	publicAnime.Get("/top", ctrl.ratings.GetTopRated)
+	publicAnime.Get("/slug/:slug", ctrl.anime.GetAnimeBySlug)
+	publicAnime.Get("/:id", ctrl.anime.GetAnimeByID)
+	publicAnime.Get("/:id/meta", ctrl.seo.GetAnimeMeta)
+	publicAnime.Get("/:id/reviews", ctrl.reviews.GetAnimeReviews)
+	publicAnime.Get("/:id/comments", ctrl.comments.GetAnimeComments)
 
-	// Initialize slider controller
-	sliderCtrl := controllers.NewSliderController()
+	// Public comment routes (read-only)
+	api.Get("/comments/:id/replies", ctrl.comments.GetCommentReplies)
 
 	// Public slider routes (read-only)
 	publicSlider := api.Group("/slider")
-	publicSlider.Get("", sliderCtrl.GetAllSliderItems)
+	publicSlider.Get("", ctrl.slider.GetAllSliderItems)
+
+	// Public episode routes (read-only) - the homepage rail is shown to guests
+	api.Get("/episodes/latest", ctrl.episodes.GetLatestEpisodes)
 
-	// Image route (public - no auth needed) - MUST be before protected middleware
-	api.Get("/upload/image/*", uploadCtrl.GetImage)
+	// Image and file routes (public - no auth needed) - MUST be before protected middleware
+	api.Get("/upload/image/*", ctrl.upload.GetImage)
+	api.Get("/upload/file/*", ctrl.upload.GetFile)
 
 	// Protected routes
 	protected := api.Group("")
 	protected.Use(middleware.AuthMiddleware(cfg))
 
 	// Auth protected routes
-	protected.Get("/auth/me", authCtrl.GetCurrentUser)
+	protected.Get("/auth/me", ctrl.auth.GetCurrentUser)
+
+	// Personal library routes (the current user's anime list)
+	library := protected.Group("/me/library")
+	library.Get("", ctrl.library.GetLibrary)
+	library.Get("/counts", ctrl.library.GetLibraryCounts)
+	library.Get("/:animeId", ctrl.library.GetLibraryEntry)
+	library.Put("/:animeId", ctrl.library.PutLibraryEntry)
+	library.Delete("/:animeId", ctrl.library.DeleteLibraryEntry)
+
+	// Playback progress routes (the current user's positions)
+	protected.Get("/me/continue-watching", ctrl.progress.GetContinueWatching)
+	protected.Get("/me/progress", ctrl.progress.GetAnimeProgress)
+
+	// Follow and notification routes (the current user's inbox)
+	protected.Get("/me/follows", ctrl.notifications.GetFollows)
+	notifications := protected.Group("/me/notifications")
+	notifications.Get("", ctrl.notifications.GetNotifications)
+	notifications.Post("/read-all", ctrl.notifications.MarkAllNotificationsRead)
+	notifications.Post("/:id/read", ctrl.notifications.MarkNotificationRead)
+	notifications.Delete("/:id", ctrl.notifications.DeleteNotification)
 
 	// Users routes
 	users := protected.Group("/users")
-	users.Get("", usersCtrl.GetAllUsers)
-	users.Get("/:id", usersCtrl.GetUserByID)
-	users.Post("", usersCtrl.CreateUser)
+	users.Get("", ctrl.users.GetAllUsers)
+	users.Get("/:id", ctrl.users.GetUserByID)
+	users.Post("", ctrl.users.CreateUser)
 	users.Put("/:id/role", func(c *fiber.Ctx) error {
 		userID := c.Params("id")
-		err := usersCtrl.UpdateUserRole(c)
+		err := ctrl.users.UpdateUserRole(c)
 		
 		// Broadcast update if successful
 		if c.Response().StatusCode() == fiber.StatusOK {
@@ -67,7 +200,7 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	})
 	users.Delete("/:id", func(c *fiber.Ctx) error {
 		userID := c.Params("id")
-		err := usersCtrl.DeleteUser(c)
+		err := ctrl.users.DeleteUser(c)
 		
 		// If successful, broadcast the deletion
 		if c.Response().StatusCode() == fiber.StatusOK {
@@ -81,47 +214,87 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 
 	// Anime routes (protected - write operations only)
 	anime := protected.Group("/anime")
-	anime.Post("", animeCtrl.CreateAnime)
-	anime.Put("/:id", animeCtrl.UpdateAnime)
-	anime.Delete("/:id", animeCtrl.DeleteAnime)
+	anime.Post("", ctrl.anime.CreateAnime)
+	anime.Put("/:id", ctrl.anime.UpdateAnime)
+	anime.Delete("/:id", ctrl.anime.DeleteAnime)
+
+	// Rating, review and follow routes (any signed-in user)
+	anime.Get("/:id/rating", ctrl.ratings.GetMyRating)
+	anime.Put("/:id/rating", ctrl.ratings.RateAnime)
+	anime.Delete("/:id/rating", ctrl.ratings.DeleteRating)
+	anime.Post("/:id/reviews", ctrl.reviews.CreateReview)
+	anime.Get("/:id/follow", ctrl.notifications.GetFollowStatus)
+	anime.Put("/:id/follow", ctrl.notifications.FollowAnime)
+	anime.Delete("/:id/follow", ctrl.notifications.UnfollowAnime)
+	reviews := protected.Group("/reviews")
+	reviews.Put("/:id", ctrl.reviews.UpdateReview)
+	reviews.Delete("/:id", ctrl.reviews.DeleteReview)
+	reviews.Put("/:id/helpful", ctrl.reviews.MarkReviewHelpful)
+	reviews.Delete("/:id/helpful", ctrl.reviews.UnmarkReviewHelpful)
+	reviews.Post("/:id/remove", middleware.RequirePermission(cfg, models.PermModerateReviews), ctrl.reviews.RemoveReview)
+
+	// Comment routes (any signed-in user, moderation requires moderate_comments)
+	anime.Post("/:id/comments", ctrl.comments.CreateAnimeComment)
+	comments := protected.Group("/comments")
+	comments.Post("/:id/replies", ctrl.comments.ReplyToComment)
+	comments.Put("/:id", ctrl.comments.UpdateComment)
+	comments.Delete("/:id", ctrl.comments.DeleteComment)
+	comments.Put("/:id/reactions/:reaction", ctrl.comments.AddReaction)
+	comments.Delete("/:id/reactions/:reaction", ctrl.comments.RemoveReaction)
+	comments.Post("/:id/moderate", middleware.RequirePermission(cfg, models.PermModerateComments), ctrl.comments.ModerateComment)
+
+	// Admin anime maintenance routes
+	adminAnime := protected.Group("/admin/anime", middleware.RequireRole(cfg, "admin"))
+	adminAnime.Get("/duplicates", ctrl.anime.GetDuplicateReport)
+	adminAnime.Post("/merge", ctrl.anime.MergeAnime)
+	adminAnime.Post("/import", ctrl.imports.ImportAnime)
+	adminAnime.Get("/:id/history", ctrl.anime.GetAnimeHistory)
+
+	// Editorial review routes (admin - change requests submitted by editors)
+	changeRequests := protected.Group("/admin/change-requests", middleware.RequirePermission(cfg, models.PermReviewChanges))
+	changeRequests.Get("", ctrl.changeRequests.GetChangeRequests)
+	changeRequests.Get("/:id", ctrl.changeRequests.GetChangeRequestByID)
+	changeRequests.Post("/:id/approve", ctrl.changeRequests.ApproveChangeRequest)
+	changeRequests.Post("/:id/reject", ctrl.changeRequests.RejectChangeRequest)
+
+	// Source report moderation routes (editors)
+	sourceReports := protected.Group("/admin/source-reports", middleware.RequirePermission(cfg, models.PermEditAnime))
+	sourceReports.Get("", ctrl.sourceReports.GetReportQueue)
+	sourceReports.Post("/:episodeId/:sourceId/resolve", ctrl.sourceReports.ResolveReports)
 
 	// Upload routes (protected)
 	upload := protected.Group("/upload")
-	upload.Post("/cover", uploadCtrl.UploadCover)
-	upload.Delete("/cover", uploadCtrl.DeleteCover)
+	upload.Post("/cover", ctrl.upload.UploadCover)
+	upload.Delete("/cover", ctrl.upload.DeleteCover)
 
 	// Episodes routes
 	episodes := protected.Group("/episodes")
-	episodes.Get("", episodesCtrl.GetEpisodesByAnimeID)
+	episodes.Get("", ctrl.episodes.GetEpisodesByAnimeID)
+	episodes.Get("/:id", ctrl.episodes.GetEpisodeByID)
+	episodes.Post("", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.CreateEpisode)
+	episodes.Put("/:id", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.UpdateEpisode)
+	episodes.Delete("/:id", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.DeleteEpisode)
+	episodes.Put("/:id/sources", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.ReplaceSources)
+	episodes.Put("/:id/sources/order", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.ReorderSources)
+	episodes.Put("/:id/sources/:sourceId/status", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.UpdateSourceStatus)
+	episodes.Get("/:id/sources/:sourceId/download", middleware.RequirePermission(cfg, models.PermDownloadEpisodes), ctrl.episodes.DownloadSource)
+	episodes.Post("/:id/sources/:sourceId/reports", ctrl.sourceReports.ReportSource)
+	episodes.Post("/:id/progress", ctrl.progress.SaveProgress)
+	episodes.Get("/:id/comments", ctrl.comments.GetEpisodeComments)
+	episodes.Post("/:id/comments", ctrl.comments.CreateEpisodeComment)
+	episodes.Put("/:id/markers", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.episodes.ReplaceMarkers)
+	episodes.Get("/:id/markers/submissions", ctrl.episodes.GetMarkerSubmissions)
+	episodes.Post("/:id/markers/submissions", ctrl.episodes.SubmitMarker)
+	episodes.Post("/:id/markers/submissions/:submissionId/vote", ctrl.episodes.VoteMarkerSubmission)
+	episodes.Post("/:id/subtitles", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.subtitles.UploadSubtitle)
+	episodes.Delete("/:id/subtitles/:trackId", middleware.RequirePermission(cfg, models.PermEditAnime), ctrl.subtitles.DeleteSubtitle)
 
 	// Protected slider routes (write operations)
 	protectedSlider := protected.Group("/slider")
-	protectedSlider.Put("", sliderCtrl.UpdateSliderItems)
-
-	// WebSocket routes
-	app.Use("/ws", handlers.WebSocketHandler)
-	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
-		// Get user ID from query
-		userID := c.Query("userID")
-		if userID == "" {
-			c.Close()
-			return
-		}
-		
-		// Pass userID to handler
-		handlers.WebSocketUpgrade(c, userID)
-	}))
+	protectedSlider.Put("", ctrl.slider.UpdateSliderItems)
 
 	// Admin route to fix old localhost URLs
 	protected.Post("/admin/fix-image-urls", func(c *fiber.Ctx) error {
-		return animeCtrl.FixImageURLs(c, cfg)
-	})
-
-	// Health check
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"status": "ok",
-			"message": "Server is running",
-		})
+		return ctrl.anime.FixImageURLs(c, cfg)
 	})
 }
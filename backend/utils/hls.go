@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var hlsURIAttrPattern = regexp.MustCompile(`URI="([^"]*)"`)
+
+// RewriteHLSPlaylist passes every URI of an HLS master or media playlist
+// through rewrite: variant and segment lines as well as the URI attributes of
+// tags such as EXT-X-KEY, EXT-X-MAP and EXT-X-MEDIA. Other lines are kept.
+func RewriteHLSPlaylist(playlist string, rewrite func(uri string) (string, error)) (string, error) {
+	playlist = strings.TrimPrefix(playlist, "\ufeff")
+	if !strings.HasPrefix(strings.TrimSpace(playlist), "#EXTM3U") {
+		return "", errors.New("not an HLS playlist")
+	}
+
+	lines := strings.Split(strings.ReplaceAll(playlist, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			var rewriteErr error
+			lines[i] = hlsURIAttrPattern.ReplaceAllStringFunc(line, func(attr string) string {
+				uri := hlsURIAttrPattern.FindStringSubmatch(attr)[1]
+				rewritten, err := rewrite(uri)
+				if err != nil {
+					rewriteErr = err
+					return attr
+				}
+				return `URI="` + rewritten + `"`
+			})
+			if rewriteErr != nil {
+				return "", rewriteErr
+			}
+		default:
+			rewritten, err := rewrite(trimmed)
+			if err != nil {
+				return "", err
+			}
+			lines[i] = rewritten
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
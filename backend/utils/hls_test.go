@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRewriteHLSPlaylist(t *testing.T) {
+	prefix := func(uri string) (string, error) {
+		return "signed:" + uri, nil
+	}
+
+	tests := []struct {
+		name     string
+		playlist string
+		rewrite  func(string) (string, error)
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "relative segments",
+			playlist: "#EXTM3U\n#EXTINF:4.0,\nseg0.ts\n#EXTINF:4.0,\nsub/seg1.ts\n#EXT-X-ENDLIST",
+			rewrite:  prefix,
+			want:     "#EXTM3U\n#EXTINF:4.0,\nsigned:seg0.ts\n#EXTINF:4.0,\nsigned:sub/seg1.ts\n#EXT-X-ENDLIST",
+		},
+		{
+			name:     "absolute variant and root-relative segment",
+			playlist: "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=800000\nhttps://cdn.example.com/720p.m3u8\n/media/ep1/1080p.m3u8",
+			rewrite:  prefix,
+			want:     "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=800000\nsigned:https://cdn.example.com/720p.m3u8\nsigned:/media/ep1/1080p.m3u8",
+		},
+		{
+			name:     "URI attributes",
+			playlist: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key.bin\",IV=0x1\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4.0,\nseg0.m4s",
+			rewrite:  prefix,
+			want:     "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"signed:key.bin\",IV=0x1\n#EXT-X-MAP:URI=\"signed:init.mp4\"\n#EXTINF:4.0,\nsigned:seg0.m4s",
+		},
+		{
+			name:     "byte order mark and CRLF",
+			playlist: "\ufeff#EXTM3U\r\n#EXTINF:4.0,\r\nseg0.ts\r\n",
+			rewrite:  prefix,
+			want:     "#EXTM3U\n#EXTINF:4.0,\nsigned:seg0.ts\n",
+		},
+		{
+			name:     "outside storage segment",
+			playlist: "#EXTM3U\n#EXTINF:4.0,\n../../secret.ts",
+			rewrite: func(uri string) (string, error) {
+				if strings.HasPrefix(uri, "..") {
+					return "", errors.New("outside the media storage")
+				}
+				return uri, nil
+			},
+			wantErr: true,
+		},
+		{
+			name:     "outside storage key",
+			playlist: "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"/etc/key\"\n#EXTINF:4.0,\nseg0.ts",
+			rewrite: func(uri string) (string, error) {
+				if strings.HasPrefix(uri, "/etc/") {
+					return "", errors.New("outside the media storage")
+				}
+				return uri, nil
+			},
+			wantErr: true,
+		},
+		{
+			name:     "not a playlist",
+			playlist: "seg0.ts\nseg1.ts",
+			rewrite:  prefix,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RewriteHLSPlaylist(tt.playlist, tt.rewrite)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("RewriteHLSPlaylist() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RewriteHLSPlaylist() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RewriteHLSPlaylist() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
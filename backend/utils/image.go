@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ImageSize returns the pixel dimensions of a JPEG, PNG, GIF or WebP image.
+// Only the header is needed, so data may be a prefix of the file.
+func ImageSize(data []byte) (int, int, error) {
+	if len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		return webpSize(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpSize reads the canvas size from a WebP header (lossy, lossless or extended)
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, fmt.Errorf("webp header too short")
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// Lossy: 14-bit dimensions after the 3-byte frame tag and start code
+		width := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+		return width, height, nil
+	case "VP8L":
+		// Lossless: signature byte followed by two packed 14-bit values
+		if data[20] != 0x2f {
+			return 0, 0, fmt.Errorf("invalid webp lossless signature")
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, nil
+	case "VP8X":
+		// Extended: 24-bit canvas width and height minus one
+		width := int(data[24]) | int(data[25])<<8 | int(data[26])<<16
+		height := int(data[27]) | int(data[28])<<8 | int(data[29])<<16
+		return width + 1, height + 1, nil
+	}
+
+	return 0, 0, fmt.Errorf("unsupported webp format")
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query parameters of a signed media URL
+const (
+	mediaParamExpires  = "exp"
+	mediaParamUser     = "uid"
+	mediaParamDownload = "dl"
+	mediaParamSig      = "sig"
+)
+
+var (
+	ErrMediaURLInvalid = errors.New("invalid media signature")
+	ErrMediaURLExpired = errors.New("media URL has expired")
+)
+
+// MediaSigner issues and checks time-limited media URLs. A URL is signed for
+// one user, and for the client IP as well when bindIP is set, so a copied
+// link stops working once it expires or is used from another network.
+type MediaSigner struct {
+	key    []byte
+	ttl    time.Duration
+	bindIP bool
+}
+
+// MediaGrant is what a verified media URL allows
+type MediaGrant struct {
+	UserID    string
+	Download  bool
+	ExpiresAt time.Time
+}
+
+func NewMediaSigner(key string, ttl time.Duration, bindIP bool) *MediaSigner {
+	return &MediaSigner{key: []byte(key), ttl: ttl, bindIP: bindIP}
+}
+
+// Sign returns rawURL with an expiry, the user and a signature added to its
+// query. Download URLs are served as attachments.
+func (s *MediaSigner) Sign(rawURL, userID, ip string, download bool) (string, time.Time, error) {
+	u, err := url.Parse(StripMediaSignature(rawURL))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+	query := u.Query()
+	query.Set(mediaParamExpires, strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set(mediaParamUser, userID)
+	if download {
+		query.Set(mediaParamDownload, "1")
+	}
+	query.Set(mediaParamSig, s.signature(u.EscapedPath(), expiresAt.Unix(), userID, download, ip))
+	u.RawQuery = query.Encode()
+
+	return u.String(), expiresAt, nil
+}
+
+// Verify checks the signature of a request for path, as sent by the client,
+// with the given query values, made from ip
+func (s *MediaSigner) Verify(path string, query url.Values, ip string) (*MediaGrant, error) {
+	expires, err := strconv.ParseInt(query.Get(mediaParamExpires), 10, 64)
+	if err != nil {
+		return nil, ErrMediaURLInvalid
+	}
+	userID := query.Get(mediaParamUser)
+	download := query.Get(mediaParamDownload) == "1"
+
+	expected := s.signature(path, expires, userID, download, ip)
+	if userID == "" || !hmac.Equal([]byte(query.Get(mediaParamSig)), []byte(expected)) {
+		return nil, ErrMediaURLInvalid
+	}
+
+	expiresAt := time.Unix(expires, 0)
+	if time.Now().After(expiresAt) {
+		return nil, ErrMediaURLExpired
+	}
+
+	return &MediaGrant{UserID: userID, Download: download, ExpiresAt: expiresAt}, nil
+}
+
+func (s *MediaSigner) signature(path string, expires int64, userID string, download bool, ip string) string {
+	if !s.bindIP {
+		ip = ""
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join([]string{
+		path,
+		strconv.FormatInt(expires, 10),
+		userID,
+		strconv.FormatBool(download),
+		ip,
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StripMediaSignature removes the signing parameters from a media URL, so
+// URLs sent back by clients are stored unsigned
+func StripMediaSignature(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	for _, param := range []string{mediaParamExpires, mediaParamUser, mediaParamDownload, mediaParamSig} {
+		query.Del(param)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMediaSignerVerify(t *testing.T) {
+	const rawURL = "https://api.example.com/media/videos/ep%201.mp4?quality=720"
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		bindIP   bool
+		download bool
+		mutate   func(path string, query url.Values) string // returns the path to verify
+		ip       string
+		wantErr  error
+	}{
+		{name: "round trip", ttl: time.Hour},
+		{name: "download round trip", ttl: time.Hour, download: true},
+		{name: "other IP without binding", ttl: time.Hour, ip: "198.51.100.9"},
+		{name: "same IP with binding", ttl: time.Hour, bindIP: true},
+		{name: "other IP with binding", ttl: time.Hour, bindIP: true, ip: "198.51.100.9", wantErr: ErrMediaURLInvalid},
+		{name: "expired", ttl: -time.Minute, wantErr: ErrMediaURLExpired},
+		{
+			name: "other path",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				return "/media/videos/ep%202.mp4"
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name: "extended expiry",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				query.Set(mediaParamExpires, "99999999999")
+				return path
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name: "other user",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				query.Set(mediaParamUser, "user2")
+				return path
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name: "download added",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				query.Set(mediaParamDownload, "1")
+				return path
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name: "signature removed",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				query.Del(mediaParamSig)
+				return path
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name: "malformed expiry",
+			ttl:  time.Hour,
+			mutate: func(path string, query url.Values) string {
+				query.Set(mediaParamExpires, "soon")
+				return path
+			},
+			wantErr: ErrMediaURLInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewMediaSigner("test-key", tt.ttl, tt.bindIP)
+			signed, expiresAt, err := signer.Sign(rawURL, "user1", "203.0.113.7", tt.download)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			u, err := url.Parse(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path, query := u.EscapedPath(), u.Query()
+			if tt.mutate != nil {
+				path = tt.mutate(path, query)
+			}
+			ip := tt.ip
+			if ip == "" {
+				ip = "203.0.113.7"
+			}
+
+			grant, err := signer.Verify(path, query, ip)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if grant.UserID != "user1" || grant.Download != tt.download || !grant.ExpiresAt.Equal(expiresAt) {
+				t.Errorf("Verify() = %+v, want user1, download %v, expiring %v", *grant, tt.download, expiresAt)
+			}
+			if query.Get("quality") != "720" {
+				t.Errorf("signed URL %s lost its own query", signed)
+			}
+		})
+	}
+}
+
+func TestMediaSignerKeys(t *testing.T) {
+	signed, _, err := NewMediaSigner("key-a", time.Hour, false).Sign("/media/a.mp4", "user1", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(signed)
+	if _, err := NewMediaSigner("key-b", time.Hour, false).Verify(u.EscapedPath(), u.Query(), ""); !errors.Is(err, ErrMediaURLInvalid) {
+		t.Errorf("Verify() with another key error = %v, want %v", err, ErrMediaURLInvalid)
+	}
+}
+
+func TestStripMediaSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"unsigned", "https://api.example.com/media/a.mp4", "https://api.example.com/media/a.mp4"},
+		{"signed", "https://api.example.com/media/a.mp4?dl=1&exp=1700000000&sig=abc&uid=u1", "https://api.example.com/media/a.mp4"},
+		{"own query kept", "/media/a.mp4?exp=1700000000&quality=720&sig=abc&uid=u1", "/media/a.mp4?quality=720"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMediaSignature(tt.url); got != tt.want {
+				t.Errorf("StripMediaSignature(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Subtitle formats accepted by SubtitleToVTT
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatASS = "ass"
+	SubtitleFormatSSA = "ssa"
+	SubtitleFormatVTT = "vtt"
+)
+
+// ErrNoSubtitleCues is returned when a subtitle file contains no usable cues
+var ErrNoSubtitleCues = errors.New("subtitle file contains no cues")
+
+type subtitleCue struct {
+	start time.Duration
+	end   time.Duration
+	text  string
+}
+
+// SubtitleToVTT converts an SRT, ASS/SSA or WebVTT file to WebVTT. Timing is
+// kept as is; styling WebVTT cannot express is dropped, while bold, italic
+// and underline survive. Files that are not UTF-8 or UTF-16 are decoded as
+// Windows-1256, the usual encoding of older Arabic subtitles.
+func SubtitleToVTT(data []byte, format string) (string, error) {
+	text, err := decodeSubtitle(data)
+	if err != nil {
+		return "", err
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var cues []subtitleCue
+	switch strings.ToLower(format) {
+	case SubtitleFormatSRT:
+		cues = parseSRT(text)
+	case SubtitleFormatASS, SubtitleFormatSSA:
+		cues = parseASS(text)
+	case SubtitleFormatVTT:
+		if !strings.HasPrefix(strings.TrimSpace(text), "WEBVTT") {
+			return "", errors.New("WebVTT file must start with WEBVTT")
+		}
+		cues = parseSRT(text)
+	default:
+		return "", fmt.Errorf("unsupported subtitle format %q", format)
+	}
+
+	if len(cues) == 0 {
+		return "", ErrNoSubtitleCues
+	}
+
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].start < cues[j].start })
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n", formatVTTTimestamp(cue.start), formatVTTTimestamp(cue.end), cue.text)
+	}
+	return b.String(), nil
+}
+
+// decodeSubtitle returns the file contents as UTF-8 without a byte order mark
+func decodeSubtitle(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("invalid UTF-16 subtitle file: %w", err)
+		}
+		return string(decoded), nil
+	case utf8.Valid(data):
+		return string(data), nil
+	}
+
+	decoded, err := charmap.Windows1256.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("unknown subtitle encoding: %w", err)
+	}
+	return string(decoded), nil
+}
+
+var srtTimingPattern = regexp.MustCompile(`^\s*([\d:.,]+)\s*-->\s*([\d:.,]+)`)
+
+// parseSRT reads SRT cues; it also reads the cues of WebVTT files, whose
+// blocks have the same layout
+func parseSRT(text string) []subtitleCue {
+	cues := []subtitleCue{}
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+
+		// The timing line follows an optional cue number or identifier
+		timing := -1
+		for i := 0; i < len(lines) && i < 2; i++ {
+			if srtTimingPattern.MatchString(lines[i]) {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		match := srtTimingPattern.FindStringSubmatch(lines[timing])
+		start, err1 := parseSubtitleTimestamp(match[1])
+		end, err2 := parseSubtitleTimestamp(match[2])
+		if err1 != nil || err2 != nil || end <= start {
+			continue
+		}
+
+		body := cleanCueText(strings.Join(lines[timing+1:], "\n"))
+		if body == "" {
+			continue
+		}
+		cues = append(cues, subtitleCue{start: start, end: end, text: body})
+	}
+	return cues
+}
+
+// parseASS reads the Dialogue lines of the [Events] section of an ASS/SSA file
+func parseASS(text string) []subtitleCue {
+	cues := []subtitleCue{}
+	inEvents := false
+	fields := []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "format":
+			fields = fields[:0]
+			for _, field := range strings.Split(value, ",") {
+				fields = append(fields, strings.ToLower(strings.TrimSpace(field)))
+			}
+		case "dialogue":
+			// The text field is last and may itself contain commas
+			values := strings.SplitN(strings.TrimSpace(value), ",", len(fields))
+			if len(values) != len(fields) {
+				continue
+			}
+			cue := subtitleCue{}
+			var err1, err2 error
+			for i, field := range fields {
+				switch field {
+				case "start":
+					cue.start, err1 = parseSubtitleTimestamp(strings.TrimSpace(values[i]))
+				case "end":
+					cue.end, err2 = parseSubtitleTimestamp(strings.TrimSpace(values[i]))
+				case "text":
+					cue.text = assTextToVTT(values[i])
+				}
+			}
+			if err1 != nil || err2 != nil || cue.end <= cue.start || cue.text == "" {
+				continue
+			}
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
+
+var assOverridePattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// assTextToVTT converts ASS override tags for bold, italic and underline to
+// WebVTT tags and drops every other override. Vector drawings are skipped.
+func assTextToVTT(text string) string {
+	if strings.Contains(text, `\p1`) {
+		return ""
+	}
+
+	open := map[string]bool{}
+	order := []string{}
+	closeAll := func() string {
+		var b strings.Builder
+		for i := len(order) - 1; i >= 0; i-- {
+			if open[order[i]] {
+				b.WriteString("</" + order[i] + ">")
+				open[order[i]] = false
+			}
+		}
+		order = order[:0]
+		return b.String()
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range assOverridePattern.FindAllStringIndex(text, -1) {
+		b.WriteString(escapeCueText(assLineBreaks(text[last:loc[0]])))
+		last = loc[1]
+
+		for _, tag := range strings.Split(text[loc[0]+1:loc[1]-1], `\`) {
+			switch {
+			case strings.HasPrefix(tag, "r"):
+				b.WriteString(closeAll())
+			case tag == "b1" || tag == "i1" || tag == "u1":
+				name := tag[:1]
+				if !open[name] {
+					open[name] = true
+					order = append(order, name)
+					b.WriteString("<" + name + ">")
+				}
+			case tag == "b0" || tag == "i0" || tag == "u0":
+				name := tag[:1]
+				if open[name] {
+					open[name] = false
+					b.WriteString("</" + name + ">")
+				}
+			}
+		}
+	}
+	b.WriteString(escapeCueText(assLineBreaks(text[last:])))
+	b.WriteString(closeAll())
+
+	return joinCueLines(b.String())
+}
+
+func assLineBreaks(text string) string {
+	text = strings.ReplaceAll(text, `\N`, "\n")
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	return strings.ReplaceAll(text, `\h`, " ")
+}
+
+var (
+	srtTagPattern   = regexp.MustCompile(`(?i)</?([a-z]+)([\s.][^>]*)?>`)
+	srtBracePattern = regexp.MustCompile(`\{\\[^}]*\}`)
+)
+
+// cleanCueText keeps the b, i and u tags of an SRT or WebVTT cue, drops other
+// tags such as <font> and ASS-style {\an8} overrides, and escapes the rest
+func cleanCueText(text string) string {
+	text = srtBracePattern.ReplaceAllString(text, "")
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range srtTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(escapeCueText(text[last:loc[0]]))
+		last = loc[1]
+
+		name := strings.ToLower(text[loc[2]:loc[3]])
+		if name == "b" || name == "i" || name == "u" {
+			if text[loc[0]+1] == '/' {
+				b.WriteString("</" + name + ">")
+			} else {
+				b.WriteString("<" + name + ">")
+			}
+		}
+	}
+	b.WriteString(escapeCueText(text[last:]))
+
+	return joinCueLines(b.String())
+}
+
+// joinCueLines trims cue lines and drops empty ones, since a blank line would
+// end the cue in WebVTT
+func joinCueLines(text string) string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeCueText escapes characters with a meaning in WebVTT cue text
+func escapeCueText(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	return strings.ReplaceAll(text, "-->", "--&gt;")
+}
+
+// parseSubtitleTimestamp parses SRT (00:01:02,345), WebVTT (01:02.345) and
+// ASS (0:01:02.34) timestamps
+func parseSubtitleTimestamp(value string) (time.Duration, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	clock, fraction, _ := strings.Cut(value, ".")
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	var total time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		total = total*60 + time.Duration(n)
+	}
+	total *= time.Second
+
+	if fraction != "" {
+		if len(fraction) > 3 {
+			fraction = fraction[:3]
+		}
+		n, err := strconv.Atoi(fraction)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		for i := len(fraction); i < 3; i++ {
+			n *= 10
+		}
+		total += time.Duration(n) * time.Millisecond
+	}
+	return total, nil
+}
+
+func formatVTTTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
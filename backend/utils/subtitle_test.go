@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const assHeader = "[Script Info]\nTitle: Test\n\n[V4+ Styles]\nFormat: Name, Fontname\nStyle: Default,Arial\n\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n"
+
+func encodeSubtitle(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSubtitleToVTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		format  string
+		want    string
+		wantErr error // nil when any error is enough
+		fails   bool
+	}{
+		{
+			name:   "srt",
+			data:   []byte("1\n00:00:01,000 --> 00:00:02,500\nHello\nworld\n\n2\n00:01:02,345 --> 00:01:04,000\nBye\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nHello\nworld\n\n00:01:02.345 --> 00:01:04.000\nBye\n",
+		},
+		{
+			name:   "srt CRLF and unordered cues",
+			data:   []byte("2\r\n00:00:05,000 --> 00:00:06,000\r\nSecond\r\n\r\n1\r\n00:00:01,000 --> 00:00:02,000\r\nFirst\r\n"),
+			format: "SRT",
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst\n\n00:00:05.000 --> 00:00:06.000\nSecond\n",
+		},
+		{
+			name:   "srt tags",
+			data:   []byte("1\n00:00:01,000 --> 00:00:02,000\n{\\an8}<i>Hi</i> <font color=\"red\"><B>there</B></font>\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<i>Hi</i> <b>there</b>\n",
+		},
+		{
+			name:   "srt escaping",
+			data:   []byte("1\n00:00:01,000 --> 00:00:02,000\nTom & Jerry --> 3 > 2\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nTom &amp; Jerry --&gt; 3 &gt; 2\n",
+		},
+		{
+			name:   "srt invalid cues skipped",
+			data:   []byte("1\n00:00:03,000 --> 00:00:02,000\nBackwards\n\n2\n00:00:04,000 --> 00:00:05,000\n\n\n3\n00:00:06,000 --> 00:00:07,000\nKept\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:06.000 --> 00:00:07.000\nKept\n",
+		},
+		{
+			name:   "vtt",
+			data:   []byte("WEBVTT\n\nintro\n00:01.000 --> 00:02.000 align:start\n<b>Hi</b>\n"),
+			format: SubtitleFormatVTT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<b>Hi</b>\n",
+		},
+		{
+			name:   "vtt without header",
+			data:   []byte("00:01.000 --> 00:02.000\nHi\n"),
+			format: SubtitleFormatVTT,
+			fails:  true,
+		},
+		{
+			name:   "ass",
+			data:   []byte(assHeader + "Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,Hello, world\\NSecond line\n"),
+			format: SubtitleFormatASS,
+			want:   "WEBVTT\n\n00:00:01.500 --> 00:00:03.000\nHello, world\nSecond line\n",
+		},
+		{
+			name:   "ass tags",
+			data:   []byte(assHeader + "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\b1}Bold {\\i1\\c&H0000FF&}both{\\r} plain {\\u1}under{\\u0} <x>\n"),
+			format: SubtitleFormatSSA,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<b>Bold <i>both</i></b> plain <u>under</u> &lt;x&gt;\n",
+		},
+		{
+			name:   "ass unclosed tags and drawings",
+			data:   []byte(assHeader + "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\p1}m 0 0 l 100 0\nDialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,{\\i1}Open\n"),
+			format: SubtitleFormatASS,
+			want:   "WEBVTT\n\n00:00:03.000 --> 00:00:04.000\n<i>Open</i>\n",
+		},
+		{
+			name:   "ass custom format",
+			data:   []byte("[Events]\nFormat: Start, End, Text\nDialogue: 0:00:01.00,0:00:02.00,Short\nComment: 0:00:03.00,0:00:04.00,Hidden\n"),
+			format: SubtitleFormatASS,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nShort\n",
+		},
+		{
+			name:   "utf-8 byte order mark",
+			data:   append([]byte{0xEF, 0xBB, 0xBF}, "1\n00:00:01,000 --> 00:00:02,000\nمرحبا\n"...),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nمرحبا\n",
+		},
+		{
+			name:   "utf-16 little endian",
+			data:   encodeSubtitle(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "1\r\n00:00:01,000 --> 00:00:02,000\r\nمرحبا\r\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nمرحبا\n",
+		},
+		{
+			name:   "utf-16 big endian",
+			data:   encodeSubtitle(t, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), "1\n00:00:01,000 --> 00:00:02,000\nمرحبا\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nمرحبا\n",
+		},
+		{
+			name:   "windows-1256",
+			data:   encodeSubtitle(t, charmap.Windows1256, "1\n00:00:01,000 --> 00:00:02,000\nسلام عليكم\n"),
+			format: SubtitleFormatSRT,
+			want:   "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nسلام عليكم\n",
+		},
+		{
+			name:    "no cues",
+			data:    []byte("just some text\n"),
+			format:  SubtitleFormatSRT,
+			wantErr: ErrNoSubtitleCues,
+			fails:   true,
+		},
+		{
+			name:    "ass without events",
+			data:    []byte("[Script Info]\nTitle: Empty\n"),
+			format:  SubtitleFormatASS,
+			wantErr: ErrNoSubtitleCues,
+			fails:   true,
+		},
+		{
+			name:   "unsupported format",
+			data:   []byte("1\n00:00:01,000 --> 00:00:02,000\nHi\n"),
+			format: "sub",
+			fails:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SubtitleToVTT(tt.data, tt.format)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("SubtitleToVTT() = %q, want an error", got)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SubtitleToVTT() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SubtitleToVTT() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SubtitleToVTT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSubtitleTimestamp(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "00:01:02,345", want: time.Minute + 2345*time.Millisecond},
+		{value: "01:02.345", want: time.Minute + 2345*time.Millisecond},
+		{value: "0:01:02.34", want: time.Minute + 2340*time.Millisecond},
+		{value: "1:00:00.5", want: time.Hour + 500*time.Millisecond},
+		{value: "00:00:01.23456", want: 1234 * time.Millisecond},
+		{value: "00:05", want: 5 * time.Second},
+		{value: "5", wantErr: true},
+		{value: "1:2:3:4", wantErr: true},
+		{value: "00:-1:00", wantErr: true},
+		{value: "00:01.ab", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSubtitleTimestamp(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSubtitleTimestamp(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseSubtitleTimestamp(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTitle lowercases a title and reduces it to letters and digits
+// separated by single spaces, so punctuation and spacing differences are ignored
+func NormalizeTitle(title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// TitleSimilarity returns the Dice coefficient of the character bigrams of two
+// normalized titles, from 0 (unrelated) to 1 (identical)
+func TitleSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+
+	bigrams := make(map[string]int, len(ra)-1)
+	for i := 0; i < len(ra)-1; i++ {
+		bigrams[string(ra[i:i+2])]++
+	}
+
+	matches := 0
+	for i := 0; i < len(rb)-1; i++ {
+		key := string(rb[i : i+2])
+		if bigrams[key] > 0 {
+			bigrams[key]--
+			matches++
+		}
+	}
+
+	return float64(2*matches) / float64(len(ra)-1+len(rb)-1)
+}
+
+// Slugify turns a title into a URL slug made of its normalized words joined by dashes
+func Slugify(title string) string {
+	return strings.ReplaceAll(NormalizeTitle(title), " ", "-")
+}
+
+// Truncate shortens text to at most max characters (runes, so multi-byte
+// scripts such as Arabic are never cut mid-character). It collapses
+// whitespace, prefers to break between words and appends an ellipsis when
+// the text was shortened. A max of zero or less gives an empty string.
+func Truncate(text string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	cut := max - 1
+	if space := strings.LastIndex(string(runes[:cut]), " "); space > 0 && len([]rune(text[:space])) > max/2 {
+		cut = len([]rune(text[:space]))
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Frieren: Beyond Journey's End", "frieren beyond journey s end"},
+		{"  Re:ZERO -Starting Life-  ", "re zero starting life"},
+		{"Mob Psycho 100 II", "mob psycho 100 ii"},
+		{"葬送のフリーレン", "葬送のフリーレン"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := NormalizeTitle(tt.title); got != tt.want {
+				t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "frieren", "frieren", 1},
+		{"identical single rune", "k", "k", 1},
+		{"too short to compare", "k", "kk", 0},
+		{"empty", "", "frieren", 0},
+		{"one shared bigram", "night", "nacht", 0.25},
+		{"repeated bigrams count once each", "aaaa", "aa", 0.5},
+		{"prefix of a longer title", "frieren", "frieren beyond journeys end", 0.375},
+		{"unrelated", "naruto", "bleach", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TitleSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TitleSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := TitleSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("TitleSimilarity(%q, %q) = %v, not symmetric with %v", tt.b, tt.a, reverse, got)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	if got, want := Slugify("Frieren: Beyond Journey's End"), "frieren-beyond-journey-s-end"; got != want {
+		t.Errorf("Slugify = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{"short text unchanged", "Frieren", 20, "Frieren"},
+		{"exact length unchanged", "Frieren", 7, "Frieren"},
+		{"whitespace collapsed", "   hello \n  world  ", 20, "hello world"},
+		{"breaks between words", "Frieren: Beyond Journey's End", 20, "Frieren: Beyond…"},
+		{"trailing punctuation dropped", "Hello, world again", 13, "Hello, world…"},
+		{"long word cut", "abcdefghijklmnop", 8, "abcdefg…"},
+		{"multi-byte runes kept whole", "مرحبا بالعالم الجميل", 10, "مرحبا بال…"},
+		{"max of one", "Frieren", 1, "…"},
+		{"zero max", "Frieren", 0, ""},
+		{"negative max", "Frieren", -5, ""},
+		{"empty text", "", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.text, tt.max); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+		})
+	}
+}